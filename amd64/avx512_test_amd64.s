@@ -7,8 +7,8 @@ TEXT ·testVALIGND(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ imm+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
     CMPQ CX, $0
     JEQ imm0_1
     CMPQ CX, $1
@@ -19,22 +19,24 @@ TEXT ·testVALIGND(SB), NOSPLIT, $0-32
     JEQ imm4_4
     CMPQ CX, $0x0000000000000008
     JEQ imm8_5
+    JMP unsupported_6
 imm0_1:
     VALIGND $0, Z1, Z0, Z2
-    JMP done_6
+    JMP done_7
 imm1_2:
     VALIGND $1, Z1, Z0, Z2
-    JMP done_6
+    JMP done_7
 imm2_3:
     VALIGND $2, Z1, Z0, Z2
-    JMP done_6
+    JMP done_7
 imm4_4:
     VALIGND $4, Z1, Z0, Z2
-    JMP done_6
+    JMP done_7
 imm8_5:
     VALIGND $8, Z1, Z0, Z2
-done_6:
-    VMOVDQU32 Z2, (DX)
+done_7:
+    VMOVDQU32 Z2, 0(DX)
+unsupported_6:
     RET
 
 TEXT ·testVALIGNQ(SB), NOSPLIT, $0-32
@@ -42,29 +44,31 @@ TEXT ·testVALIGNQ(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ imm+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     CMPQ CX, $0
-    JEQ imm0_7
+    JEQ imm0_8
     CMPQ CX, $1
-    JEQ imm1_8
+    JEQ imm1_9
     CMPQ CX, $0x0000000000000002
-    JEQ imm2_9
+    JEQ imm2_10
     CMPQ CX, $0x0000000000000004
-    JEQ imm4_10
-imm0_7:
+    JEQ imm4_11
+    JMP unsupported_12
+imm0_8:
     VALIGNQ $0, Z1, Z0, Z2
-    JMP done_11
-imm1_8:
+    JMP done_13
+imm1_9:
     VALIGNQ $1, Z1, Z0, Z2
-    JMP done_11
-imm2_9:
+    JMP done_13
+imm2_10:
     VALIGNQ $2, Z1, Z0, Z2
-    JMP done_11
-imm4_10:
+    JMP done_13
+imm4_11:
     VALIGNQ $4, Z1, Z0, Z2
-done_11:
-    VMOVDQU64 Z2, (DX)
+done_13:
+    VMOVDQU64 Z2, 0(DX)
+unsupported_12:
     RET
 
 TEXT ·testVPBLENDMQ(SB), NOSPLIT, $0-32
@@ -72,11 +76,11 @@ TEXT ·testVPBLENDMQ(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ mask+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     KMOVB CX, K1
     VPBLENDMQ Z1, Z0, K1, Z2
-    VMOVDQU64 Z2, (DX)
+    VMOVDQU64 Z2, 0(DX)
     RET
 
 TEXT ·testVPBLENDMD(SB), NOSPLIT, $0-32
@@ -84,54 +88,56 @@ TEXT ·testVPBLENDMD(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ mask+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
     KMOVW CX, K1
     VPBLENDMD Z1, Z0, K1, Z2
-    VMOVDQU32 Z2, (DX)
+    VMOVDQU32 Z2, 0(DX)
     RET
 
 TEXT ·testVPERMQ(SB), NOSPLIT, $0-24
     MOVQ src+0(FP), AX
     MOVQ imm+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU64 (AX), Z0
+    VMOVDQU64 0(AX), Z0
     CMPQ BX, $0
-    JEQ imm00_12
+    JEQ imm00_14
     CMPQ BX, $0x0000000000000055
-    JEQ imm55_13
+    JEQ imm55_15
     CMPQ BX, $0x00000000000000aa
-    JEQ immAA_14
+    JEQ immAA_16
     CMPQ BX, $0x00000000000000d8
-    JEQ immD8_15
+    JEQ immD8_17
     CMPQ BX, $0x000000000000001b
-    JEQ imm1B_16
-imm00_12:
+    JEQ imm1B_18
+    JMP unsupported_19
+imm00_14:
     VPERMQ $0x00, Z0, Z1
-    JMP done_17
-imm55_13:
+    JMP done_20
+imm55_15:
     VPERMQ $0x55, Z0, Z1
-    JMP done_17
-immAA_14:
+    JMP done_20
+immAA_16:
     VPERMQ $0xAA, Z0, Z1
-    JMP done_17
-immD8_15:
+    JMP done_20
+immD8_17:
     VPERMQ $0xD8, Z0, Z1
-    JMP done_17
-imm1B_16:
+    JMP done_20
+imm1B_18:
     VPERMQ $0x1B, Z0, Z1
-done_17:
-    VMOVDQU64 Z1, (CX)
+done_20:
+    VMOVDQU64 Z1, 0(CX)
+unsupported_19:
     RET
 
 TEXT ·testVPERMD(SB), NOSPLIT, $0-24
     MOVQ idx+0(FP), AX
     MOVQ src+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
     VPERMD Z1, Z0, Z2
-    VMOVDQU32 Z2, (CX)
+    VMOVDQU32 Z2, 0(CX)
     RET
 
 TEXT ·testVPERMI2Q(SB), NOSPLIT, $0-32
@@ -139,11 +145,11 @@ TEXT ·testVPERMI2Q(SB), NOSPLIT, $0-32
     MOVQ idx+8(FP), BX
     MOVQ src2+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
-    VMOVDQU64 (CX), Z2
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
+    VMOVDQU64 0(CX), Z2
     VPERMI2Q Z2, Z0, Z1
-    VMOVDQU64 Z1, (DX)
+    VMOVDQU64 Z1, 0(DX)
     RET
 
 TEXT ·testVPERMT2Q(SB), NOSPLIT, $0-32
@@ -151,11 +157,11 @@ TEXT ·testVPERMT2Q(SB), NOSPLIT, $0-32
     MOVQ idx+8(FP), BX
     MOVQ src2+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
-    VMOVDQU64 (CX), Z2
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
+    VMOVDQU64 0(CX), Z2
     VPERMT2Q Z2, Z1, Z0
-    VMOVDQU64 Z0, (DX)
+    VMOVDQU64 Z0, 0(DX)
     RET
 
 TEXT ·testVSHUFI64X2(SB), NOSPLIT, $0-32
@@ -163,24 +169,26 @@ TEXT ·testVSHUFI64X2(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ imm+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     CMPQ CX, $0
-    JEQ imm00_18
+    JEQ imm00_21
     CMPQ CX, $0x0000000000000044
-    JEQ imm44_19
+    JEQ imm44_22
     CMPQ CX, $0x00000000000000ee
-    JEQ immEE_20
-imm00_18:
+    JEQ immEE_23
+    JMP unsupported_24
+imm00_21:
     VSHUFI64X2 $0x00, Z1, Z0, Z2
-    JMP done_21
-imm44_19:
+    JMP done_25
+imm44_22:
     VSHUFI64X2 $0x44, Z1, Z0, Z2
-    JMP done_21
-immEE_20:
+    JMP done_25
+immEE_23:
     VSHUFI64X2 $0xEE, Z1, Z0, Z2
-done_21:
-    VMOVDQU64 Z2, (DX)
+done_25:
+    VMOVDQU64 Z2, 0(DX)
+unsupported_24:
     RET
 
 TEXT ·testVSHUFPD(SB), NOSPLIT, $0-32
@@ -188,97 +196,101 @@ TEXT ·testVSHUFPD(SB), NOSPLIT, $0-32
     MOVQ src2+8(FP), BX
     MOVQ imm+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     CMPQ CX, $0
-    JEQ imm00_22
+    JEQ imm00_26
     CMPQ CX, $0x0000000000000055
-    JEQ imm55_23
+    JEQ imm55_27
     CMPQ CX, $0x00000000000000aa
-    JEQ immAA_24
+    JEQ immAA_28
     CMPQ CX, $0x00000000000000ff
-    JEQ immFF_25
-imm00_22:
+    JEQ immFF_29
+    JMP unsupported_30
+imm00_26:
     VSHUFPD $0x00, Z1, Z0, Z2
-    JMP done_26
-imm55_23:
+    JMP done_31
+imm55_27:
     VSHUFPD $0x55, Z1, Z0, Z2
-    JMP done_26
-immAA_24:
+    JMP done_31
+immAA_28:
     VSHUFPD $0xAA, Z1, Z0, Z2
-    JMP done_26
-immFF_25:
+    JMP done_31
+immFF_29:
     VSHUFPD $0xFF, Z1, Z0, Z2
-done_26:
-    VMOVDQU64 Z2, (DX)
+done_31:
+    VMOVDQU64 Z2, 0(DX)
+unsupported_30:
     RET
 
 TEXT ·testVPSHUFD(SB), NOSPLIT, $0-24
     MOVQ src+0(FP), AX
     MOVQ imm+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU32 (AX), Z0
+    VMOVDQU32 0(AX), Z0
     CMPQ BX, $0
-    JEQ imm00_27
+    JEQ imm00_32
     CMPQ BX, $0x000000000000001b
-    JEQ imm1B_28
+    JEQ imm1B_33
     CMPQ BX, $0x00000000000000b1
-    JEQ immB1_29
+    JEQ immB1_34
     CMPQ BX, $0x00000000000000d8
-    JEQ immD8_30
-imm00_27:
+    JEQ immD8_35
+    JMP unsupported_36
+imm00_32:
     VPSHUFD $0x00, Z0, Z1
-    JMP done_31
-imm1B_28:
+    JMP done_37
+imm1B_33:
     VPSHUFD $0x1B, Z0, Z1
-    JMP done_31
-immB1_29:
+    JMP done_37
+immB1_34:
     VPSHUFD $0xB1, Z0, Z1
-    JMP done_31
-immD8_30:
+    JMP done_37
+immD8_35:
     VPSHUFD $0xD8, Z0, Z1
-done_31:
-    VMOVDQU32 Z1, (CX)
+done_37:
+    VMOVDQU32 Z1, 0(CX)
+unsupported_36:
     RET
 
 TEXT ·testVPUNPCKLDQ(SB), NOSPLIT, $0-24
     MOVQ src1+0(FP), AX
     MOVQ src2+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
     VPUNPCKLDQ Z1, Z0, Z2
-    VMOVDQU32 Z2, (CX)
+    VMOVDQU32 Z2, 0(CX)
     RET
 
 TEXT ·testVPUNPCKHDQ(SB), NOSPLIT, $0-24
     MOVQ src1+0(FP), AX
     MOVQ src2+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
     VPUNPCKHDQ Z1, Z0, Z2
-    VMOVDQU32 Z2, (CX)
+    VMOVDQU32 Z2, 0(CX)
     RET
 
 TEXT ·testVPUNPCKLQDQ(SB), NOSPLIT, $0-24
     MOVQ src1+0(FP), AX
     MOVQ src2+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     VPUNPCKLQDQ Z1, Z0, Z2
-    VMOVDQU64 Z2, (CX)
+    VMOVDQU64 Z2, 0(CX)
     RET
 
 TEXT ·testVPUNPCKHQDQ(SB), NOSPLIT, $0-24
     MOVQ src1+0(FP), AX
     MOVQ src2+8(FP), BX
     MOVQ dst+16(FP), CX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
     VPUNPCKHQDQ Z1, Z0, Z2
-    VMOVDQU64 Z2, (CX)
+    VMOVDQU64 Z2, 0(CX)
     RET
 
 TEXT ·testVPMADD52LUQ(SB), NOSPLIT, $0-32
@@ -286,11 +298,11 @@ TEXT ·testVPMADD52LUQ(SB), NOSPLIT, $0-32
     MOVQ b+8(FP), BX
     MOVQ c+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
-    VMOVDQU64 (CX), Z2
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
+    VMOVDQU64 0(CX), Z2
     VPMADD52LUQ Z1, Z0, Z2
-    VMOVDQU64 Z2, (DX)
+    VMOVDQU64 Z2, 0(DX)
     RET
 
 TEXT ·testVPMADD52HUQ(SB), NOSPLIT, $0-32
@@ -298,11 +310,11 @@ TEXT ·testVPMADD52HUQ(SB), NOSPLIT, $0-32
     MOVQ b+8(FP), BX
     MOVQ c+16(FP), CX
     MOVQ dst+24(FP), DX
-    VMOVDQU64 (AX), Z0
-    VMOVDQU64 (BX), Z1
-    VMOVDQU64 (CX), Z2
+    VMOVDQU64 0(AX), Z0
+    VMOVDQU64 0(BX), Z1
+    VMOVDQU64 0(CX), Z2
     VPMADD52HUQ Z1, Z0, Z2
-    VMOVDQU64 Z2, (DX)
+    VMOVDQU64 Z2, 0(DX)
     RET
 
 TEXT ·testVPTERNLOGD(SB), NOSPLIT, $0-40
@@ -311,24 +323,26 @@ TEXT ·testVPTERNLOGD(SB), NOSPLIT, $0-40
     MOVQ c+16(FP), CX
     MOVQ imm+24(FP), R8
     MOVQ dst+32(FP), DX
-    VMOVDQU32 (AX), Z0
-    VMOVDQU32 (BX), Z1
-    VMOVDQU32 (CX), Z2
+    VMOVDQU32 0(AX), Z0
+    VMOVDQU32 0(BX), Z1
+    VMOVDQU32 0(CX), Z2
     CMPQ R8, $0x0000000000000096
-    JEQ imm96_32
+    JEQ imm96_38
     CMPQ R8, $0x0000000000000080
-    JEQ imm80_33
+    JEQ imm80_39
     CMPQ R8, $0x00000000000000fe
-    JEQ immFE_34
-imm96_32:
+    JEQ immFE_40
+    JMP unsupported_41
+imm96_38:
     VPTERNLOGD $0x96, Z2, Z1, Z0
-    JMP done_35
-imm80_33:
+    JMP done_42
+imm80_39:
     VPTERNLOGD $0x80, Z2, Z1, Z0
-    JMP done_35
-immFE_34:
+    JMP done_42
+immFE_40:
     VPTERNLOGD $0xFE, Z2, Z1, Z0
-done_35:
-    VMOVDQU32 Z0, (DX)
+done_42:
+    VMOVDQU32 Z0, 0(DX)
+unsupported_41:
     RET
 
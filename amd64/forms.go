@@ -0,0 +1,482 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// forms lists the legal forms of the emitted mnemonics. Operands are in Go assembly order (sources first,
+// destination last). Each form is a space separated list of operand specs; a spec lists alternatives separated
+// by "/":
+//
+//	r8 r16 r32 r64          general purpose register
+//	cl                      CX, as a shift count
+//	xmm ymm zmm             vector register
+//	k                       mask register
+//	kw                      write mask: a mask register other than K0
+//	k?                      optional write mask
+//	m m8 m16 ... m512       memory (the width is documentation only)
+//	imm8 imm16 imm32 imm64  immediate, range checked
+//	simm32                  immediate sign-extended to 64 bits, in [-2³¹, 2³¹)
+//	rel                     label
+//
+// In a form, V stands for xmm, ymm and zmm, W for ymm and zmm, and mV (mW) for the memory operand of the same
+// width. Mnemonics without an entry, and the DATA and GLOBL directives, are not checked. For the ".BCST" and ".Z"
+// suffixes, the forms of the base mnemonic are used; ".BCST" requires a memory first operand and ".Z" a write mask.
+var forms = map[string][]string{
+	// integer instructions
+	"ADDQ": aluQ, "ADCQ": aluQ, "SUBQ": aluQ, "SBBQ": aluQ, "ANDQ": aluQ, "ORQ": aluQ, "XORQ": aluQ,
+	"ADCXQ":   {"r64/m64 r64"},
+	"ADOXQ":   {"r64/m64 r64"},
+	"MULXQ":   {"r64/m64 r64 r64"},
+	"MULQ":    {"r64/m64"},
+	"IMULQ":   {"r64/m64 r64"},
+	"IMUL3Q":  {"simm32 r64/m64 r64"},
+	"IMUL3L":  {"imm32 r32/m32 r32"},
+	"NEGQ":    {"r64/m64"},
+	"NOTQ":    {"r64/m64"},
+	"INCQ":    {"r64/m64"},
+	"DECQ":    {"r64/m64"},
+	"PUSHQ":   {"simm32/r64/m64"},
+	"POPQ":    {"r64/m64"},
+	"BSFQ":    {"r64/m64 r64"},
+	"TZCNTQ":  {"r64/m64 r64"},
+	"BTQ":     {"imm8/r64 r64/m64"},
+	"CMPB":    {"r8/m8 imm8/r8", "r8 m8"},
+	"CMPL":    {"r32/m32 imm32/r32", "r32 m32"},
+	"CMPQ":    {"r64/m64 simm32/r64", "r64 m64"},
+	"TESTB":   {"imm8/r8 r8/m8"},
+	"TESTQ":   {"simm32/r64 r64/m64"},
+	"MOVQ":    {"imm64/r64/m64/xmm r64", "simm32/r64/xmm m64", "r64/m64/xmm xmm"},
+	"MOVD":    {"imm64/r64/m64/xmm r64", "simm32/r64/xmm m64", "r64/m64/xmm xmm"},
+	"MOVL":    {"imm32/r32/m32 r32", "imm32/r32 m32"},
+	"MOVNTIQ": {"r64 m64"},
+	"LEAQ":    {"m r64"},
+	"LEAL":    {"m r32"},
+	"XCHGQ":   {"r64/m64 r64", "r64 m64"},
+	"XCHGL":   {"r32/m32 r32", "r32 m32"},
+	"CMOVQCC": cmovQ, "CMOVQCS": cmovQ, "CMOVQEQ": cmovQ,
+	"CMOVLCC": {"r32/m32 r32"},
+	"SHLQ":    shiftQ, "SHRQ": append([]string{"imm8/cl r64 r64/m64"}, shiftQ...), "SARQ": shiftQ, "ROLQ": shiftQ, "RORQ": shiftQ,
+	"SHRD":  {"imm8/cl r64 r64/m64"},
+	"SHRXQ": {"r64 r64/m64 r64"},
+
+	// control flow
+	"JMP": jump, "JNE": jump, "JNZ": jump, "JEQ": jump, "JCS": jump, "JCC": jump, "JGE": jump, "JL": jump,
+	"CALL": {"rel/r64/m64"},
+
+	// prefetch
+	"PREFETCHT0": {"m8"}, "PREFETCHT1": {"m8"}, "PREFETCHT2": {"m8"}, "PREFETCHNTA": {"m8"},
+
+	// SSE
+	"XORPS":  {"xmm/m128 xmm"},
+	"MOVUPS": {"xmm/m128 xmm", "xmm m128"},
+	"PEXTRD": {"imm8 xmm r32/m32"},
+	"PEXTRQ": {"imm8 xmm r64/m64"},
+
+	// mask registers
+	"KMOVB": kmov("r32", "m8"), "KMOVW": kmov("r32", "m16"), "KMOVD": kmov("r32", "m32"), "KMOVQ": kmov("r64", "m64"),
+	"KADDB": kkk, "KADDW": kkk, "KADDD": kkk, "KADDQ": kkk,
+	"KANDW": kkk, "KANDD": kkk, "KANDQ": kkk,
+	"KORW": kkk, "KORD": kkk, "KORQ": kkk,
+	"KXORW": kkk, "KXORD": kkk, "KXORQ": kkk,
+	"KNOTB": kk, "KNOTW": kk, "KNOTD": kk, "KNOTQ": kk,
+	"KORTESTW": kk, "KORTESTD": kk, "KORTESTQ": kk,
+	"KTESTB": kk, "KTESTW": kk, "KTESTD": kk, "KTESTQ": kk,
+	"KSHIFTLW": ikk, "KSHIFTLD": ikk, "KSHIFTLQ": ikk,
+	"KSHIFTRW": ikk, "KSHIFTRD": ikk, "KSHIFTRQ": ikk,
+
+	// AVX2 / AVX-512
+	"VPADDD": vvv, "VPADDQ": vvv, "VPSUBD": vvv, "VPSUBQ": vvv,
+	"VPMULLD": vvv, "VPMULLQ": vvv, "VPMULUDQ": vvv, "VPMADDWD": vvv,
+	"VPMADD52LUQ": vvv, "VPMADD52HUQ": vvv,
+	"VPANDD": vvv, "VPANDQ": vvv, "VPANDNQ": vvv, "VPORQ": vvv, "VPXORQ": vvv, "VXORPS": vvv,
+	"VPMAXSD": vvv, "VPMAXSQ": vvv, "VPMAXUD": vvv, "VPMAXUQ": vvv,
+	"VPMINSD": vvv, "VPMINSQ": vvv, "VPMINUD": vvv, "VPMINUQ": vvv,
+	"VPSLLVD": vvv, "VPSLLVQ": vvv, "VPSRLVD": vvv, "VPSRLVQ": vvv, "VPSRAVD": vvv, "VPSRAVQ": vvv,
+	"VPROLVD": vvv, "VPROLVQ": vvv, "VPRORVD": vvv, "VPRORVQ": vvv,
+	"VPSHLDVD": vvv, "VPSHLDVQ": vvv, "VPSHRDVD": vvv, "VPSHRDVQ": vvv,
+	"VPUNPCKLDQ": vvv, "VPUNPCKHDQ": vvv, "VPUNPCKLQDQ": vvv, "VPUNPCKHQDQ": vvv,
+	"VPERMI2D": vvv, "VPERMI2Q": vvv, "VPERMT2D": vvv, "VPERMT2Q": vvv, "VPERMW": vvv,
+	"VPBLENDMD": vvv, "VPBLENDMQ": vvv,
+	"VPERMD":   {"mW/W W k? W"},
+	"VPERMQ":   {"imm8 mW/W k? W", "mW/W W k? W"},
+	"VPCMPEQB": {"mV/V V V", "mV/V V k? k"},
+	"VPCMPUD":  {"imm8 mV/V V k? k"},
+	"VPCMPUQ":  {"imm8 mV/V V k? k"},
+	"VPTESTMD": {"mV/V V k? k"},
+	"VPTESTMQ": {"mV/V V k? k"},
+	"VPMOVQ2M": {"V k"},
+	"VPSLLD":   shiftV, "VPSLLQ": shiftV, "VPSRLD": shiftV, "VPSRLQ": shiftV, "VPSRAQ": shiftV,
+	"VPSLLDQ": {"imm8 mV/V V"},
+	"VPSRLDQ": {"imm8 mV/V V"},
+	"VPSHUFD": ivv, "VPSHUFHW": ivv, "VPSHUFLW": ivv,
+	"VPROLD": ivv, "VPROLQ": ivv, "VPRORD": ivv, "VPRORQ": ivv,
+	"VALIGND": ivvv, "VALIGNQ": ivvv, "VPTERNLOGD": ivvv, "VPTERNLOGQ": ivvv,
+	"VPSHLDD": ivvv, "VPSHLDQ": ivvv, "VPSHRDD": ivvv, "VPSHRDQ": ivvv, "VSHUFPD": ivvv,
+	"VSHUFI32X4": {"imm8 mW/W W k? W"}, "VSHUFI64X2": {"imm8 mW/W W k? W"},
+	"VSHUFF32X4": {"imm8 mW/W W k? W"}, "VSHUFF64X2": {"imm8 mW/W W k? W"},
+	"VPBLENDD": {"imm8 m128/xmm xmm xmm", "imm8 m256/ymm ymm ymm"},
+	"VPABSD":   vv, "VPABSQ": vv, "VPCONFLICTD": vv, "VPCONFLICTQ": vv, "VPLZCNTD": vv, "VPLZCNTQ": vv,
+	"VMOVSHDUP": vv,
+	"VMOVDQU32": vmov, "VMOVDQU64": vmov, "VMOVDQA32": vmov, "VMOVDQA64": vmov,
+	"VPEXPANDD": vv, "VPEXPANDQ": vv,
+	"VPCOMPRESSD": {"V k? V/mV"}, "VPCOMPRESSQ": {"V k? V/mV"},
+	"VPBROADCASTD": {"m32/xmm/r32 k? V"},
+	"VPBROADCASTQ": {"m64/xmm/r64 k? V"},
+	"VPGATHERDD":   {"m kw V"}, "VPGATHERDQ": {"m kw V"}, "VPGATHERQD": {"m kw ymm", "m kw xmm"}, "VPGATHERQQ": {"m kw V"},
+	"VPSCATTERDD": {"V kw m"}, "VPSCATTERDQ": {"V kw m"}, "VPSCATTERQD": {"ymm kw m", "xmm kw m"}, "VPSCATTERQQ": {"V kw m"},
+	"VEXTRACTI32X8": {"imm8 zmm k? m256/ymm"},
+	"VEXTRACTI64X4": {"imm8 zmm k? m256/ymm"},
+	"VEXTRACTI64X2": {"imm8 W k? m128/xmm"},
+	"VINSERTI64X4":  {"imm8 m256/ymm zmm k? zmm"},
+	"VINSERTI64X2":  {"imm8 m128/xmm W k? W"},
+	"VPMOVQD":       narrow, "VPMOVDW": narrow,
+	"VPMOVZXDQ": widen, "VPMOVZXWD": widen,
+	"VPINSRD": {"imm8 r32/m32 xmm xmm"},
+	"VPINSRQ": {"imm8 r64/m64 xmm xmm"},
+	"VPEXTRQ": {"imm8 xmm r64/m64"},
+	"VMOVQ":   {"r64/m64/xmm xmm", "xmm r64/m64"},
+	"VMOVD":   {"r32/m32 xmm", "xmm r32/m32"},
+}
+
+// shared forms of instruction families
+var (
+	aluQ   = []string{"simm32/r64/m64 r64", "simm32/r64 m64"}
+	cmovQ  = []string{"r64/m64 r64"}
+	shiftQ = []string{"imm8/cl r64/m64"}
+	jump   = []string{"rel"}
+	kk     = []string{"k k"}
+	kkk    = []string{"k k k"}
+	ikk    = []string{"imm8 k k"}
+	vv     = []string{"mV/V k? V"}
+	vvv    = []string{"mV/V V k? V"}
+	ivv    = []string{"imm8 mV/V k? V"}
+	ivvv   = []string{"imm8 mV/V V k? V"}
+	shiftV = []string{"imm8 mV/V k? V", "m128/xmm V k? V"}
+	vmov   = []string{"mV/V k? V", "V k? mV"}
+	narrow = []string{"zmm k? m256/ymm", "ymm k? m128/xmm", "xmm k? m64/xmm"}
+	widen  = []string{"m256/ymm k? zmm", "m128/xmm k? ymm", "m64/xmm k? xmm"}
+)
+
+func kmov(r, m string) []string {
+	return []string{"k/" + m + " k", "k " + m, r + " k", "k " + r}
+}
+
+// form is a form of an instruction once expanded: one list of alternatives per operand
+type form [][]string
+
+func (f form) String() string {
+	specs := make([]string, len(f))
+	for i, alternatives := range f {
+		specs[i] = strings.ReplaceAll(strings.Join(alternatives, "/"), "kw", "k")
+	}
+	return strings.Join(specs, " ")
+}
+
+func (f form) hasWriteMask() bool {
+	for _, alternatives := range f {
+		for _, s := range alternatives {
+			if s == "kw" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandedForms is forms, with the V and W width placeholders and the optional write masks expanded
+var expandedForms = func() map[string][]form {
+	m := make(map[string][]form, len(forms))
+	for mnemonic, specs := range forms {
+		for _, spec := range specs {
+			m[mnemonic] = append(m[mnemonic], expandForm(spec)...)
+		}
+	}
+	return m
+}()
+
+func expandForm(spec string) []form {
+	var specs []string
+	switch {
+	case strings.Contains(spec, "V"):
+		for _, w := range []string{"xmm:128", "ymm:256", "zmm:512"} {
+			specs = append(specs, substituteWidth(spec, "V", w))
+		}
+	case strings.Contains(spec, "W"):
+		for _, w := range []string{"ymm:256", "zmm:512"} {
+			specs = append(specs, substituteWidth(spec, "W", w))
+		}
+	default:
+		specs = []string{spec}
+	}
+
+	var r []form
+	for _, s := range specs {
+		tokens := strings.Fields(s)
+		with, without := make(form, 0, len(tokens)), make(form, 0, len(tokens))
+		optional := false
+		for _, t := range tokens {
+			if t == "k?" {
+				optional = true
+				with = append(with, []string{"kw"})
+				continue
+			}
+			with = append(with, strings.Split(t, "/"))
+			without = append(without, strings.Split(t, "/"))
+		}
+		r = append(r, with)
+		if optional {
+			r = append(r, without)
+		}
+	}
+	return r
+}
+
+// substituteWidth replaces the placeholder p (and mp) in spec; w is "name:bits"
+func substituteWidth(spec, p, w string) string {
+	name, bits, _ := strings.Cut(w, ":")
+	tokens := strings.Fields(spec)
+	for i, t := range tokens {
+		alternatives := strings.Split(t, "/")
+		for j, a := range alternatives {
+			switch a {
+			case p:
+				alternatives[j] = name
+			case "m" + p:
+				alternatives[j] = "m" + bits
+			}
+		}
+		tokens[i] = strings.Join(alternatives, "/")
+	}
+	return strings.Join(tokens, " ")
+}
+
+// operandClass is what checkForm knows of an operand
+type operandClass struct {
+	kind     operandKind
+	width    int    // vector registers: 128, 256 or 512
+	name     string // registers
+	imm      int64
+	immKnown bool
+	immBits  int // sized immediates (Imm8, UImm32, ...): their width
+}
+
+type operandKind int
+
+const (
+	kindUnknown operandKind = iota // macros, symbolic names: matches any spec
+	kindRegister
+	kindVector
+	kindMask
+	kindMemory
+	kindImm
+	kindLabel
+)
+
+func (c operandClass) String() string {
+	switch c.kind {
+	case kindRegister:
+		return "a general purpose register"
+	case kindVector:
+		return fmt.Sprintf("a %s register", vectorNames[c.width])
+	case kindMask:
+		return "a mask register"
+	case kindMemory:
+		return "a memory operand"
+	case kindImm:
+		return "an immediate"
+	case kindLabel:
+		return "a label"
+	}
+	return "an unknown operand"
+}
+
+var vectorNames = map[int]string{128: "xmm", 256: "ymm", 512: "zmm"}
+
+var (
+	vectorRegisterName = regexp.MustCompile(`^[XYZ]([0-9]|[12][0-9]|3[01])$`)
+	maskRegisterName   = regexp.MustCompile(`^K[0-7]$`)
+	gpRegisterNames    = map[string]bool{
+		"AX": true, "BX": true, "CX": true, "DX": true, "SI": true, "DI": true, "BP": true, "SP": true,
+		"R8": true, "R9": true, "R10": true, "R11": true, "R12": true, "R13": true, "R14": true, "R15": true,
+	}
+)
+
+func classify(o Operand) operandClass {
+	switch t := o.(type) {
+	case Imm:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true}
+	case Imm8:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 8}
+	case Imm16:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 16}
+	case Imm32:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 32}
+	case UImm8:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 8}
+	case UImm16:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 16}
+	case UImm32:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 32}
+	case UImm64:
+		return operandClass{kind: kindImm, imm: int64(t), immKnown: true, immBits: 64}
+	case Mem, FPArg, SymRef:
+		return operandClass{kind: kindMemory}
+	case Label:
+		return operandClass{kind: kindLabel}
+	}
+	// registers may be given as Raw text, which may hold anything
+	s := strings.TrimSpace(o.String())
+	switch {
+	case strings.HasPrefix(s, "$"):
+		c := operandClass{kind: kindImm}
+		if v, err := strconv.ParseInt(s[1:], 0, 64); err == nil {
+			c.imm, c.immKnown = v, true
+		} else if v, err := strconv.ParseUint(s[1:], 0, 64); err == nil {
+			c.imm, c.immKnown = int64(v), true
+		}
+		return c
+	case strings.Contains(s, "("):
+		return operandClass{kind: kindMemory}
+	case gpRegisterNames[s]:
+		return operandClass{kind: kindRegister, name: s}
+	case vectorRegisterName.MatchString(s):
+		return operandClass{kind: kindVector, width: map[byte]int{'X': 128, 'Y': 256, 'Z': 512}[s[0]]}
+	case maskRegisterName.MatchString(s):
+		return operandClass{kind: kindMask, name: s}
+	}
+	return operandClass{kind: kindUnknown}
+}
+
+// matchSpec returns an empty string if c matches the operand spec s, and the reason why otherwise
+func matchSpec(s string, c operandClass) string {
+	if c.kind == kindUnknown {
+		return ""
+	}
+	switch s {
+	case "r8", "r16", "r32", "r64":
+		if c.kind == kindRegister {
+			return ""
+		}
+	case "cl":
+		if c.kind == kindRegister {
+			if c.name == "CX" {
+				return ""
+			}
+			return "is not CX"
+		}
+	case "xmm", "ymm", "zmm":
+		if c.kind == kindVector && vectorNames[c.width] == s {
+			return ""
+		}
+	case "k":
+		if c.kind == kindMask {
+			return ""
+		}
+	case "kw":
+		if c.kind == kindMask {
+			if c.name == "K0" {
+				return "is K0, which can't be used as a write mask"
+			}
+			return ""
+		}
+	case "rel":
+		if c.kind == kindLabel {
+			return ""
+		}
+	case "imm8", "imm16", "imm32", "imm64", "simm32":
+		if c.kind == kindImm {
+			signed, width, _ := strings.Cut(s, "imm")
+			bits, _ := strconv.Atoi(width)
+			if c.immBits > bits {
+				return fmt.Sprintf("is a %d-bit immediate", c.immBits)
+			}
+			// sign-extended immediates must be in the signed range, others may also be given unsigned
+			limit := int64(1) << bits
+			if signed == "s" {
+				limit >>= 1
+			}
+			if !c.immKnown || bits == 64 || (c.imm >= -(1<<(bits-1)) && c.imm < limit) {
+				return ""
+			}
+			return fmt.Sprintf("is out of the %s range", s)
+		}
+	default:
+		if strings.HasPrefix(s, "m") && c.kind == kindMemory {
+			return ""
+		}
+	}
+	return "is " + c.String()
+}
+
+// matchAlternatives returns an empty string if c matches one of the alternatives, and the reason why not
+// otherwise. An operand of the right kind failing a finer check (range, width, K0) gives the most useful reason.
+func matchAlternatives(alternatives []string, c operandClass) string {
+	generic := "is " + c.String()
+	reason := generic
+	for _, s := range alternatives {
+		r := matchSpec(s, c)
+		if r == "" {
+			return ""
+		}
+		if r != generic {
+			reason = r
+		}
+	}
+	return reason
+}
+
+// checkForm returns an error if the operands don't match any form of the instruction
+func checkForm(instruction string, operands []Operand) error {
+	mnemonic, suffix, _ := strings.Cut(instruction, ".")
+	candidates, ok := expandedForms[mnemonic]
+	if !ok {
+		return nil
+	}
+	classes := make([]operandClass, len(operands))
+	for i, o := range operands {
+		classes[i] = classify(o)
+	}
+	zeroing := strings.Contains("."+suffix+".", ".Z.")
+	broadcast := strings.Contains("."+suffix+".", ".BCST.")
+	if broadcast && classes[0].kind != kindMemory && classes[0].kind != kindUnknown {
+		return fmt.Errorf("%s: operand 0 (%s) is %s, expected a memory operand to broadcast", instruction, operands[0], classes[0])
+	}
+
+	// closest form of the same arity, for the error message: the one with the most matching operands
+	var closest form
+	best, wrong, reason := -1, 0, ""
+	for _, f := range candidates {
+		if len(f) != len(operands) || (zeroing && !f.hasWriteMask()) {
+			continue
+		}
+		matching, first, r := 0, -1, ""
+		for i := range f {
+			if ri := matchAlternatives(f[i], classes[i]); ri == "" {
+				matching++
+			} else if first < 0 {
+				first, r = i, ri
+			}
+		}
+		if first < 0 {
+			return nil
+		}
+		if matching > best {
+			closest, best, wrong, reason = f, matching, first, r
+		}
+	}
+	if closest == nil {
+		expected := make([]string, 0, len(forms[mnemonic]))
+		for _, s := range forms[mnemonic] {
+			expected = append(expected, mnemonic+" "+s)
+		}
+		return fmt.Errorf("%s: no form takes %d operands, expected one of: %s", instruction, len(operands), strings.Join(expected, "; "))
+	}
+	return fmt.Errorf("%s: operand %d (%s) %s, expected %s %s", instruction, wrong, operands[wrong], reason, instruction, closest)
+}
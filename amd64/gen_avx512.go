@@ -6,6 +6,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/consensys/bavard/amd64"
@@ -42,35 +43,44 @@ func main() {
 	generateVPMADD52LUQ(asm)
 	generateVPMADD52HUQ(asm)
 	generateVPTERNLOGD(asm)
+
+	if err := asm.Err(); err != nil {
+		panic(err)
+	}
+	for _, w := range asm.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
 }
 
 // generateVALIGND generates test function for VALIGND instruction
 func generateVALIGND(asm *amd64.Amd64) {
 	asm.FnHeader("testVALIGND", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("imm+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
-	asm.VMOVDQU32("(BX)", amd64.Z1)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
-	asm.CMPQ(amd64.CX, 0)
+	asm.CMPQ(amd64.CX, amd64.Imm(0))
 	l0 := asm.NewLabel("imm0")
 	asm.JEQ(l0)
-	asm.CMPQ(amd64.CX, 1)
+	asm.CMPQ(amd64.CX, amd64.Imm(1))
 	l1 := asm.NewLabel("imm1")
 	asm.JEQ(l1)
-	asm.CMPQ(amd64.CX, 2)
+	asm.CMPQ(amd64.CX, amd64.Imm(2))
 	l2 := asm.NewLabel("imm2")
 	asm.JEQ(l2)
-	asm.CMPQ(amd64.CX, 4)
+	asm.CMPQ(amd64.CX, amd64.Imm(4))
 	l4 := asm.NewLabel("imm4")
 	asm.JEQ(l4)
-	asm.CMPQ(amd64.CX, 8)
+	asm.CMPQ(amd64.CX, amd64.Imm(8))
 	l8 := asm.NewLabel("imm8")
 	asm.JEQ(l8)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l0)
 	asm.WriteLn("    VALIGND $0, Z1, Z0, Z2")
@@ -93,7 +103,8 @@ func generateVALIGND(asm *amd64.Amd64) {
 	asm.WriteLn("    VALIGND $8, Z1, Z0, Z2")
 
 	asm.LABEL(done)
-	asm.VMOVDQU32(amd64.Z2, "(DX)")
+	asm.VMOVDQU32(amd64.Z2, amd64.Mem{Base: amd64.DX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -101,26 +112,28 @@ func generateVALIGND(asm *amd64.Amd64) {
 func generateVALIGNQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVALIGNQ", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("imm+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
-	asm.CMPQ(amd64.CX, 0)
+	asm.CMPQ(amd64.CX, amd64.Imm(0))
 	l0 := asm.NewLabel("imm0")
 	asm.JEQ(l0)
-	asm.CMPQ(amd64.CX, 1)
+	asm.CMPQ(amd64.CX, amd64.Imm(1))
 	l1 := asm.NewLabel("imm1")
 	asm.JEQ(l1)
-	asm.CMPQ(amd64.CX, 2)
+	asm.CMPQ(amd64.CX, amd64.Imm(2))
 	l2 := asm.NewLabel("imm2")
 	asm.JEQ(l2)
-	asm.CMPQ(amd64.CX, 4)
+	asm.CMPQ(amd64.CX, amd64.Imm(4))
 	l4 := asm.NewLabel("imm4")
 	asm.JEQ(l4)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l0)
 	asm.WriteLn("    VALIGNQ $0, Z1, Z0, Z2")
@@ -139,7 +152,8 @@ func generateVALIGNQ(asm *amd64.Amd64) {
 	asm.WriteLn("    VALIGNQ $4, Z1, Z0, Z2")
 
 	asm.LABEL(done)
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -147,18 +161,18 @@ func generateVALIGNQ(asm *amd64.Amd64) {
 func generateVPBLENDMQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPBLENDMQ", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("mask+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "mask", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 	asm.KMOVB(amd64.CX, amd64.K1)
 
 	asm.WriteLn("    VPBLENDMQ Z1, Z0, K1, Z2")
 
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -166,18 +180,18 @@ func generateVPBLENDMQ(asm *amd64.Amd64) {
 func generateVPBLENDMD(asm *amd64.Amd64) {
 	asm.FnHeader("testVPBLENDMD", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("mask+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "mask", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
-	asm.VMOVDQU32("(BX)", amd64.Z1)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 	asm.KMOVW(amd64.CX, amd64.K1)
 
 	asm.WriteLn("    VPBLENDMD Z1, Z0, K1, Z2")
 
-	asm.VMOVDQU32(amd64.Z2, "(DX)")
+	asm.VMOVDQU32(amd64.Z2, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -185,27 +199,29 @@ func generateVPBLENDMD(asm *amd64.Amd64) {
 func generateVPERMQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPERMQ", 0, 24)
 
-	asm.MOVQ("src+0(FP)", amd64.AX)
-	asm.MOVQ("imm+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
 
-	asm.CMPQ(amd64.BX, 0x00)
+	asm.CMPQ(amd64.BX, amd64.Imm(0x00))
 	l00 := asm.NewLabel("imm00")
 	asm.JEQ(l00)
-	asm.CMPQ(amd64.BX, 0x55)
+	asm.CMPQ(amd64.BX, amd64.Imm(0x55))
 	l55 := asm.NewLabel("imm55")
 	asm.JEQ(l55)
-	asm.CMPQ(amd64.BX, 0xAA)
+	asm.CMPQ(amd64.BX, amd64.Imm(0xAA))
 	lAA := asm.NewLabel("immAA")
 	asm.JEQ(lAA)
-	asm.CMPQ(amd64.BX, 0xD8)
+	asm.CMPQ(amd64.BX, amd64.Imm(0xD8))
 	lD8 := asm.NewLabel("immD8")
 	asm.JEQ(lD8)
-	asm.CMPQ(amd64.BX, 0x1B)
+	asm.CMPQ(amd64.BX, amd64.Imm(0x1B))
 	l1B := asm.NewLabel("imm1B")
 	asm.JEQ(l1B)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l00)
 	asm.WriteLn("    VPERMQ $0x00, Z0, Z1")
@@ -228,7 +244,8 @@ func generateVPERMQ(asm *amd64.Amd64) {
 	asm.WriteLn("    VPERMQ $0x1B, Z0, Z1")
 
 	asm.LABEL(done)
-	asm.VMOVDQU64(amd64.Z1, "(CX)")
+	asm.VMOVDQU64(amd64.Z1, amd64.Mem{Base: amd64.CX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -236,17 +253,17 @@ func generateVPERMQ(asm *amd64.Amd64) {
 func generateVPERMD(asm *amd64.Amd64) {
 	asm.FnHeader("testVPERMD", 0, 24)
 
-	asm.MOVQ("idx+0(FP)", amd64.AX)
-	asm.MOVQ("src+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "idx", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0) // idx
-	asm.VMOVDQU32("(BX)", amd64.Z1) // src
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0) // idx
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1) // src
 
 	// Go asm VPERMD is: VPERMD src, idx, dst
 	asm.VPERMD(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU32(amd64.Z2, "(CX)")
+	asm.VMOVDQU32(amd64.Z2, amd64.Mem{Base: amd64.CX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -254,18 +271,18 @@ func generateVPERMD(asm *amd64.Amd64) {
 func generateVPERMI2Q(asm *amd64.Amd64) {
 	asm.FnHeader("testVPERMI2Q", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("idx+8(FP)", amd64.BX)
-	asm.MOVQ("src2+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "idx", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
-	asm.VMOVDQU64("(CX)", amd64.Z2)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.CX}, amd64.Z2)
 
 	asm.VPERMI2Q(amd64.Z2, amd64.Z0, amd64.Z1)
 
-	asm.VMOVDQU64(amd64.Z1, "(DX)")
+	asm.VMOVDQU64(amd64.Z1, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -273,18 +290,18 @@ func generateVPERMI2Q(asm *amd64.Amd64) {
 func generateVPERMT2Q(asm *amd64.Amd64) {
 	asm.FnHeader("testVPERMT2Q", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("idx+8(FP)", amd64.BX)
-	asm.MOVQ("src2+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "idx", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
-	asm.VMOVDQU64("(CX)", amd64.Z2)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.CX}, amd64.Z2)
 
 	asm.VPERMT2Q(amd64.Z2, amd64.Z1, amd64.Z0)
 
-	asm.VMOVDQU64(amd64.Z0, "(DX)")
+	asm.VMOVDQU64(amd64.Z0, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -292,23 +309,25 @@ func generateVPERMT2Q(asm *amd64.Amd64) {
 func generateVSHUFI64X2(asm *amd64.Amd64) {
 	asm.FnHeader("testVSHUFI64X2", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("imm+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
-	asm.CMPQ(amd64.CX, 0x00)
+	asm.CMPQ(amd64.CX, amd64.Imm(0x00))
 	l00 := asm.NewLabel("imm00")
 	asm.JEQ(l00)
-	asm.CMPQ(amd64.CX, 0x44)
+	asm.CMPQ(amd64.CX, amd64.Imm(0x44))
 	l44 := asm.NewLabel("imm44")
 	asm.JEQ(l44)
-	asm.CMPQ(amd64.CX, 0xEE)
+	asm.CMPQ(amd64.CX, amd64.Imm(0xEE))
 	lEE := asm.NewLabel("immEE")
 	asm.JEQ(lEE)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l00)
 	asm.WriteLn("    VSHUFI64X2 $0x00, Z1, Z0, Z2")
@@ -323,7 +342,8 @@ func generateVSHUFI64X2(asm *amd64.Amd64) {
 	asm.WriteLn("    VSHUFI64X2 $0xEE, Z1, Z0, Z2")
 
 	asm.LABEL(done)
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -331,26 +351,28 @@ func generateVSHUFI64X2(asm *amd64.Amd64) {
 func generateVSHUFPD(asm *amd64.Amd64) {
 	asm.FnHeader("testVSHUFPD", 0, 32)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("imm+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
-	asm.CMPQ(amd64.CX, 0x00)
+	asm.CMPQ(amd64.CX, amd64.Imm(0x00))
 	l00 := asm.NewLabel("imm00")
 	asm.JEQ(l00)
-	asm.CMPQ(amd64.CX, 0x55)
+	asm.CMPQ(amd64.CX, amd64.Imm(0x55))
 	l55 := asm.NewLabel("imm55")
 	asm.JEQ(l55)
-	asm.CMPQ(amd64.CX, 0xAA)
+	asm.CMPQ(amd64.CX, amd64.Imm(0xAA))
 	lAA := asm.NewLabel("immAA")
 	asm.JEQ(lAA)
-	asm.CMPQ(amd64.CX, 0xFF)
+	asm.CMPQ(amd64.CX, amd64.Imm(0xFF))
 	lFF := asm.NewLabel("immFF")
 	asm.JEQ(lFF)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l00)
 	asm.WriteLn("    VSHUFPD $0x00, Z1, Z0, Z2")
@@ -369,7 +391,8 @@ func generateVSHUFPD(asm *amd64.Amd64) {
 	asm.WriteLn("    VSHUFPD $0xFF, Z1, Z0, Z2")
 
 	asm.LABEL(done)
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -377,24 +400,26 @@ func generateVSHUFPD(asm *amd64.Amd64) {
 func generateVPSHUFD(asm *amd64.Amd64) {
 	asm.FnHeader("testVPSHUFD", 0, 24)
 
-	asm.MOVQ("src+0(FP)", amd64.AX)
-	asm.MOVQ("imm+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
 
-	asm.CMPQ(amd64.BX, 0x00)
+	asm.CMPQ(amd64.BX, amd64.Imm(0x00))
 	l00 := asm.NewLabel("imm00")
 	asm.JEQ(l00)
-	asm.CMPQ(amd64.BX, 0x1B)
+	asm.CMPQ(amd64.BX, amd64.Imm(0x1B))
 	l1B := asm.NewLabel("imm1B")
 	asm.JEQ(l1B)
-	asm.CMPQ(amd64.BX, 0xB1)
+	asm.CMPQ(amd64.BX, amd64.Imm(0xB1))
 	lB1 := asm.NewLabel("immB1")
 	asm.JEQ(lB1)
-	asm.CMPQ(amd64.BX, 0xD8)
+	asm.CMPQ(amd64.BX, amd64.Imm(0xD8))
 	lD8 := asm.NewLabel("immD8")
 	asm.JEQ(lD8)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l00)
 	asm.WriteLn("    VPSHUFD $0x00, Z0, Z1")
@@ -413,7 +438,8 @@ func generateVPSHUFD(asm *amd64.Amd64) {
 	asm.WriteLn("    VPSHUFD $0xD8, Z0, Z1")
 
 	asm.LABEL(done)
-	asm.VMOVDQU32(amd64.Z1, "(CX)")
+	asm.VMOVDQU32(amd64.Z1, amd64.Mem{Base: amd64.CX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -421,16 +447,16 @@ func generateVPSHUFD(asm *amd64.Amd64) {
 func generateVPUNPCKLDQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPUNPCKLDQ", 0, 24)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
-	asm.VMOVDQU32("(BX)", amd64.Z1)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
 	asm.VPUNPCKLDQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU32(amd64.Z2, "(CX)")
+	asm.VMOVDQU32(amd64.Z2, amd64.Mem{Base: amd64.CX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -438,16 +464,16 @@ func generateVPUNPCKLDQ(asm *amd64.Amd64) {
 func generateVPUNPCKHDQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPUNPCKHDQ", 0, 24)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
-	asm.VMOVDQU32("(BX)", amd64.Z1)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
 	asm.VPUNPCKHDQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU32(amd64.Z2, "(CX)")
+	asm.VMOVDQU32(amd64.Z2, amd64.Mem{Base: amd64.CX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -455,16 +481,16 @@ func generateVPUNPCKHDQ(asm *amd64.Amd64) {
 func generateVPUNPCKLQDQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPUNPCKLQDQ", 0, 24)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
 	asm.VPUNPCKLQDQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU64(amd64.Z2, "(CX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.CX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -472,16 +498,16 @@ func generateVPUNPCKLQDQ(asm *amd64.Amd64) {
 func generateVPUNPCKHQDQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPUNPCKHQDQ", 0, 24)
 
-	asm.MOVQ("src1+0(FP)", amd64.AX)
-	asm.MOVQ("src2+8(FP)", amd64.BX)
-	asm.MOVQ("dst+16(FP)", amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "src1", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "src2", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 16}, amd64.CX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
 
 	asm.VPUNPCKHQDQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU64(amd64.Z2, "(CX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.CX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -489,18 +515,18 @@ func generateVPUNPCKHQDQ(asm *amd64.Amd64) {
 func generateVPMADD52LUQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPMADD52LUQ", 0, 32)
 
-	asm.MOVQ("a+0(FP)", amd64.AX)
-	asm.MOVQ("b+8(FP)", amd64.BX)
-	asm.MOVQ("c+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "a", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "b", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "c", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
-	asm.VMOVDQU64("(CX)", amd64.Z2)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.CX}, amd64.Z2)
 
 	asm.VPMADD52LUQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -508,18 +534,18 @@ func generateVPMADD52LUQ(asm *amd64.Amd64) {
 func generateVPMADD52HUQ(asm *amd64.Amd64) {
 	asm.FnHeader("testVPMADD52HUQ", 0, 32)
 
-	asm.MOVQ("a+0(FP)", amd64.AX)
-	asm.MOVQ("b+8(FP)", amd64.BX)
-	asm.MOVQ("c+16(FP)", amd64.CX)
-	asm.MOVQ("dst+24(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "a", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "b", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "c", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 24}, amd64.DX)
 
-	asm.VMOVDQU64("(AX)", amd64.Z0)
-	asm.VMOVDQU64("(BX)", amd64.Z1)
-	asm.VMOVDQU64("(CX)", amd64.Z2)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.BX}, amd64.Z1)
+	asm.VMOVDQU64(amd64.Mem{Base: amd64.CX}, amd64.Z2)
 
 	asm.VPMADD52HUQ(amd64.Z1, amd64.Z0, amd64.Z2)
 
-	asm.VMOVDQU64(amd64.Z2, "(DX)")
+	asm.VMOVDQU64(amd64.Z2, amd64.Mem{Base: amd64.DX})
 	asm.RET()
 	asm.WriteLn("")
 }
@@ -527,25 +553,27 @@ func generateVPMADD52HUQ(asm *amd64.Amd64) {
 func generateVPTERNLOGD(asm *amd64.Amd64) {
 	asm.FnHeader("testVPTERNLOGD", 0, 40)
 
-	asm.MOVQ("a+0(FP)", amd64.AX)
-	asm.MOVQ("b+8(FP)", amd64.BX)
-	asm.MOVQ("c+16(FP)", amd64.CX)
-	asm.MOVQ("imm+24(FP)", amd64.R8)
-	asm.MOVQ("dst+32(FP)", amd64.DX)
+	asm.MOVQ(amd64.FPArg{Name: "a", Offset: 0}, amd64.AX)
+	asm.MOVQ(amd64.FPArg{Name: "b", Offset: 8}, amd64.BX)
+	asm.MOVQ(amd64.FPArg{Name: "c", Offset: 16}, amd64.CX)
+	asm.MOVQ(amd64.FPArg{Name: "imm", Offset: 24}, amd64.R8)
+	asm.MOVQ(amd64.FPArg{Name: "dst", Offset: 32}, amd64.DX)
 
-	asm.VMOVDQU32("(AX)", amd64.Z0)
-	asm.VMOVDQU32("(BX)", amd64.Z1)
-	asm.VMOVDQU32("(CX)", amd64.Z2)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.AX}, amd64.Z0)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.BX}, amd64.Z1)
+	asm.VMOVDQU32(amd64.Mem{Base: amd64.CX}, amd64.Z2)
 
-	asm.CMPQ(amd64.R8, 0x96)
+	asm.CMPQ(amd64.R8, amd64.Imm(0x96))
 	l96 := asm.NewLabel("imm96")
 	asm.JEQ(l96)
-	asm.CMPQ(amd64.R8, 0x80)
+	asm.CMPQ(amd64.R8, amd64.Imm(0x80))
 	l80 := asm.NewLabel("imm80")
 	asm.JEQ(l80)
-	asm.CMPQ(amd64.R8, 0xFE)
+	asm.CMPQ(amd64.R8, amd64.Imm(0xFE))
 	lFE := asm.NewLabel("immFE")
 	asm.JEQ(lFE)
+	unsupported := asm.NewLabel("unsupported")
+	asm.JMP(unsupported) // leave dst untouched rather than falling into the first case
 
 	asm.LABEL(l96)
 	asm.WriteLn("    VPTERNLOGD $0x96, Z2, Z1, Z0")
@@ -560,7 +588,8 @@ func generateVPTERNLOGD(asm *amd64.Amd64) {
 	asm.WriteLn("    VPTERNLOGD $0xFE, Z2, Z1, Z0")
 
 	asm.LABEL(done)
-	asm.VMOVDQU32(amd64.Z0, "(DX)")
+	asm.VMOVDQU32(amd64.Z0, amd64.Mem{Base: amd64.DX})
+	asm.LABEL(unsupported)
 	asm.RET()
 	asm.WriteLn("")
 }
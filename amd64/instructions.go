@@ -3,9 +3,14 @@
 
 // Package amd64 contains wrapper to amd64 instructions in Go assembly.
 // note that while this package is public, it is tailored for github.com/consensys/goff and github.com/consensys/gurvy
+//
+// Instruction methods take typed operands (see Operand): registers, sized immediates like Imm8 or UImm32,
+// memory references and labels. Raw operands, written verbatim, are for macro arguments and other syntax the
+// types don't cover.
 package amd64
 
 import (
+	"errors"
 	"fmt"
 	"io"
 )
@@ -14,12 +19,49 @@ type Amd64 struct {
 	w            io.Writer
 	labelCounter int
 	defineMode   bool
+	errs         []error
+
+	strict    bool   // see Strict
+	recording bool   // see Record
+	nodes     []Node // recorded nodes
+
+	scope          *labelScope        // labels of the function being written
+	labelFunctions map[Label][]string // functions defining each label
+	warnings       []string
 }
 
 func NewAmd64(w io.Writer) *Amd64 {
 	return &Amd64{w: w}
 }
 
+// Err returns the errors which occurred while generating, joined with errors.Join: invalid operands or operands
+// matching no legal form of the instruction (the instruction is written anyway, see forms), and errors of the
+// underlying writer. It also reports the label errors of the functions opened with FnHeader: jumps to labels
+// the function doesn't define, and labels defined more than once (see Warnings for unreachable blocks). A
+// function is checked when the next one starts; the function being written is checked by each call to Err.
+// Generators should check it once done.
+func (amd64 *Amd64) Err() error {
+	errs := amd64.errs
+	if amd64.scope != nil {
+		current, _ := amd64.scope.check(amd64.labelFunctions)
+		errs = append(errs[:len(errs):len(errs)], current...)
+	}
+	return errors.Join(errs...)
+}
+
+// Strict makes amd64 panic on the first error instead of recording it for Err, for generators which don't
+// check Err
+func (amd64 *Amd64) Strict() {
+	amd64.strict = true
+}
+
+func (amd64 *Amd64) addErr(err error) {
+	if amd64.strict {
+		panic(err)
+	}
+	amd64.errs = append(amd64.errs, err)
+}
+
 func (amd64 *Amd64) StartDefine() {
 	if amd64.defineMode {
 		panic("Define cannot be nested")
@@ -35,220 +77,222 @@ func (amd64 *Amd64) RET() {
 	amd64.WriteLn("    RET")
 }
 
-func (amd64 *Amd64) MULXQ(src, lo, hi interface{}, comment ...string) {
+func (amd64 *Amd64) MULXQ(src, lo, hi Operand, comment ...string) {
 	amd64.writeOp(comment, "MULXQ", src, lo, hi)
 }
 
-func (amd64 *Amd64) SUBQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) SUBQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "SUBQ", r1, r2)
 }
 
-func (amd64 *Amd64) SBBQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) SBBQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "SBBQ", r1, r2)
 }
 
-func (amd64 *Amd64) ADDQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ADDQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ADDQ", r1, r2)
 }
 
-func (amd64 *Amd64) ADCQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ADCQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ADCQ", r1, r2)
 }
 
-func (amd64 *Amd64) ADOXQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ADOXQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ADOXQ", r1, r2)
 }
 
-func (amd64 *Amd64) ADCXQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ADCXQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ADCXQ", r1, r2)
 }
 
-func (amd64 *Amd64) XORQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) XORQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "XORQ", r1, r2)
 }
 
-func (amd64 *Amd64) XORPS(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) XORPS(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "XORPS", r1, r2)
 }
 
-func (amd64 *Amd64) MOVQ(r1, r2 interface{}, comment ...string) {
-	if op(r1) != op(r2) {
-		amd64.writeOp(comment, "MOVQ", r1, r2)
+func (amd64 *Amd64) MOVQ(r1, r2 Operand, comment ...string) {
+	if r1 != nil && r2 != nil && r1.check() == nil && r1.String() == r2.String() {
+		return
 	}
+	amd64.writeOp(comment, "MOVQ", r1, r2)
 }
 
-func (amd64 *Amd64) MOVL(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) MOVL(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "MOVL", r1, r2)
 }
 
 // MOVD
-func (amd64 *Amd64) MOVD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) MOVD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "MOVD", r1, r2)
 }
 
-func (amd64 *Amd64) BTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) BTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "BTQ", r1, r2)
 }
 
-func (amd64 *Amd64) MOVUPS(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) MOVUPS(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "MOVUPS", r1, r2)
 }
 
-func (amd64 *Amd64) LEAL(offset, r1, r2 interface{}, comment ...string) {
-	amd64.writeOp(comment, "LEAL", fmt.Sprintf("%s(%s)", op(offset), op(r1)), r2)
+// LEAL writes LEAL offset(base), r
+func (amd64 *Amd64) LEAL(offset int, base Register, r Operand, comment ...string) {
+	amd64.writeOp(comment, "LEAL", Mem{Base: base, Disp: offset}, r)
 }
 
-func (amd64 *Amd64) ANDQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ANDQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ANDQ", r1, r2)
 }
 
-func (amd64 *Amd64) BSFQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) BSFQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "BSFQ", r1, r2)
 }
 
-func (amd64 *Amd64) MOVNTIQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) MOVNTIQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "MOVNTIQ", r1, r2)
 }
 
-func (amd64 *Amd64) SHRQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) SHRQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "SHRQ", r1, r2)
 }
 
-func (amd64 *Amd64) SHLQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) SHLQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "SHLQ", r1, r2)
 }
 
-func (amd64 *Amd64) SHRQw(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) SHRQw(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "SHRQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) SHRDw(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) SHRDw(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "SHRD", r1, r2, r3)
 }
 
-func (amd64 *Amd64) SHRXQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) SHRXQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "SHRXQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) TZCNTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) TZCNTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "TZCNTQ", r1, r2)
 }
 
-func (amd64 *Amd64) INCQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) INCQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "INCQ", r1)
 }
 
-func (amd64 *Amd64) DECQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) DECQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "DECQ", r1)
 }
 
-func (amd64 *Amd64) PUSHQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) PUSHQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "PUSHQ", r1)
 }
 
-func (amd64 *Amd64) POPQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) POPQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "POPQ", r1)
 }
 
-func (amd64 *Amd64) IMULQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) IMULQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "IMULQ", r1, r2)
 }
 
-func (amd64 *Amd64) IMUL3Q(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) IMUL3Q(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "IMUL3Q", r1, r2, r3)
 }
 
-func (amd64 *Amd64) XCHGL(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) XCHGL(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "XCHGL", r1, r2)
 }
 
-func (amd64 *Amd64) IMUL3L(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) IMUL3L(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "IMUL3L", r1, r2, r3)
 }
 
-func (amd64 *Amd64) MULQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) MULQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "MULQ", r1)
 }
 
-func (amd64 *Amd64) CMPB(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMPB(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMPB", r1, r2)
 }
 
-func (amd64 *Amd64) CMPL(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMPL(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMPL", r1, r2)
 }
 
-func (amd64 *Amd64) CMPQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMPQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMPQ", r1, r2)
 }
 
-func (amd64 *Amd64) ORQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ORQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ORQ", r1, r2)
 }
 
-func (amd64 *Amd64) TESTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) TESTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "TESTQ", r1, r2)
 }
 
-func (amd64 *Amd64) XCHGQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) XCHGQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "XCHGQ", r1, r2)
 }
 
-func (amd64 *Amd64) CMOVQCC(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMOVQCC(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMOVQCC", r1, r2)
 }
 
-func (amd64 *Amd64) CMOVLCC(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMOVLCC(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMOVLCC", r1, r2)
 }
 
-func (amd64 *Amd64) CMOVQEQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMOVQEQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMOVQEQ", r1, r2)
 }
 
-func (amd64 *Amd64) CMOVQCS(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) CMOVQCS(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "CMOVQCS", r1, r2)
 }
 
 func (amd64 *Amd64) LABEL(l Label) {
-	amd64.WriteLn(string(l) + ":")
+	amd64.emit(Node{Kind: NodeLabel, Label: l})
 }
 
 // JNE x86 JNZ Jump short if not zero (ZF=0).
 func (amd64 *Amd64) JNE(label Label, comment ...string) {
-	amd64.writeOp(comment, "JNE", string(label))
+	amd64.writeOp(comment, "JNE", label)
 }
 
 // JEQ: x86 JZ Jump short if zero (ZF = 1).
 func (amd64 *Amd64) JEQ(label Label, comment ...string) {
-	amd64.writeOp(comment, "JEQ", string(label))
+	amd64.writeOp(comment, "JEQ", label)
 }
 
 // JCS x86 JB Jump short if below (CF=1).
 func (amd64 *Amd64) JCS(label Label, comment ...string) {
-	amd64.writeOp(comment, "JCS", string(label))
+	amd64.writeOp(comment, "JCS", label)
 }
 
 // JCC x86 JNB Jump short if not below (CF=0).
 func (amd64 *Amd64) JCC(label Label, comment ...string) {
-	amd64.writeOp(comment, "JCC", string(label))
+	amd64.writeOp(comment, "JCC", label)
 }
 
 // JGE
 func (amd64 *Amd64) JGE(label Label, comment ...string) {
-	amd64.writeOp(comment, "JGE", string(label))
+	amd64.writeOp(comment, "JGE", label)
 }
 
 func (amd64 *Amd64) JMP(label Label, comment ...string) {
-	amd64.writeOp(comment, "JMP", string(label))
+	amd64.writeOp(comment, "JMP", label)
 }
 
 func (amd64 *Amd64) JL(label Label, comment ...string) {
-	amd64.writeOp(comment, "JL", string(label))
+	amd64.writeOp(comment, "JL", label)
 }
 
 func (amd64 *Amd64) Comment(s string) {
-	amd64.WriteLn("    // " + s)
+	amd64.emit(Node{Kind: NodeComment, Comment: s})
 }
 
 func (amd64 *Amd64) FnHeader(funcName string, stackSize, argSize int, reserved ...Register) Registers {
@@ -259,6 +303,11 @@ func (amd64 *Amd64) FnHeader(funcName string, stackSize, argSize int, reserved .
 		header = "TEXT ·%s(SB), $%d-%d"
 	}
 
+	amd64.endFunction()
+	if amd64.labelFunctions == nil {
+		amd64.labelFunctions = make(map[Label][]string)
+	}
+	amd64.scope = newLabelScope(funcName)
 	amd64.WriteLn(fmt.Sprintf(header, funcName, stackSize, argSize))
 	r := NewRegisters()
 	for _, rr := range reserved {
@@ -268,72 +317,45 @@ func (amd64 *Amd64) FnHeader(funcName string, stackSize, argSize int, reserved .
 }
 
 func (amd64 *Amd64) WriteLn(s string) {
-	amd64.write(s + "\n")
+	amd64.emit(Node{Kind: NodeRaw, Text: s})
 }
 
-func (amd64 *Amd64) write(s string) {
-	// in define mode, if the last character is a newline, we insert a "\" before it
-	if amd64.defineMode && len(s) > 0 && s[len(s)-1] == '\n' {
-		amd64.w.Write([]byte(s[:len(s)-1] + "\\\n"))
-		return
+// writeLine writes s and a newline; in define mode, the newline is escaped with a "\"
+func (amd64 *Amd64) writeLine(s string, define bool) {
+	if define {
+		s += "\\"
+	}
+	if _, err := io.WriteString(amd64.w, s+"\n"); err != nil {
+		amd64.addErr(err)
 	}
-	amd64.w.Write([]byte(s))
 }
 
-func (amd64 *Amd64) writeOp(comments []string, instruction string, r0 interface{}, r ...interface{}) {
-	amd64.write(fmt.Sprintf("    %s %s", instruction, op(r0)))
-	l := len(op(r0))
-	for _, rn := range r {
-		amd64.write(fmt.Sprintf(", %s", op(rn)))
-		l += (2 + len(op(rn)))
-	}
-	if len(comments) == 1 {
-		l = 50 - l
-		for i := 0; i < l; i++ {
-			amd64.write(" ")
+func (amd64 *Amd64) writeOp(comments []string, instruction string, operands ...Operand) {
+	// invalid instructions are written anyway, so that the assembler fails on them if Err isn't checked
+	valid := true
+	for i, o := range operands {
+		if o == nil {
+			operands[i] = Raw("<missing operand>")
+			amd64.addErr(fmt.Errorf("%s: operand %d: missing operand", instruction, i))
+			valid = false
+		} else if err := o.check(); err != nil {
+			amd64.addErr(fmt.Errorf("%s: operand %d: %w", instruction, i, err))
+			valid = false
 		}
-		amd64.write("// " + comments[0])
 	}
-	amd64.write("\n")
-}
-
-func op(i interface{}) string {
-	switch t := i.(type) {
-	case string:
-		return t
-	case VectorRegister:
-		return string(t)
-	case Register:
-		return string(t)
-	case MaskRegister:
-		return string(t)
-	case int:
-		switch t {
-		case 0:
-			return "$0"
-		case 1:
-			return "$1"
-		default:
-			return fmt.Sprintf("$%#016x", uint64(t))
-		}
-	case uint64:
-		switch t {
-		case 0:
-			return "$0"
-		case 1:
-			return "$1"
-		default:
-			return fmt.Sprintf("$%#016x", t)
+	if valid {
+		if err := checkForm(instruction, operands); err != nil {
+			amd64.addErr(err)
 		}
 	}
-	panic("unsupported interface type")
+	amd64.emit(Instruction(instruction, operands, comments...))
 }
 
-func (amd64 *Amd64) TESTB(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) TESTB(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "TESTB", r1, r2)
 }
 
-func (amd64 *Amd64) JNZ(label interface{}, comment ...string) {
+func (amd64 *Amd64) JNZ(label Label, comment ...string) {
 	amd64.writeOp(comment, "JNZ", label)
 }
 
@@ -353,7 +375,7 @@ func (amd64 *Amd64) JNZ(label interface{}, comment ...string) {
 // Example:
 //
 //	PREFETCHT0 2048(AX) // prefetch data 2KB ahead
-func (amd64 *Amd64) PREFETCHT0(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) PREFETCHT0(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "PREFETCHT0", r1)
 }
 
@@ -365,7 +387,7 @@ func (amd64 *Amd64) PREFETCHT0(r1 interface{}, comment ...string) {
 // Forms:
 //
 //	PREFETCHT1 m8
-func (amd64 *Amd64) PREFETCHT1(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) PREFETCHT1(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "PREFETCHT1", r1)
 }
 
@@ -377,7 +399,7 @@ func (amd64 *Amd64) PREFETCHT1(r1 interface{}, comment ...string) {
 // Forms:
 //
 //	PREFETCHT2 m8
-func (amd64 *Amd64) PREFETCHT2(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) PREFETCHT2(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "PREFETCHT2", r1)
 }
 
@@ -389,7 +411,7 @@ func (amd64 *Amd64) PREFETCHT2(r1 interface{}, comment ...string) {
 // Forms:
 //
 //	PREFETCHNTA m8
-func (amd64 *Amd64) PREFETCHNTA(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) PREFETCHNTA(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "PREFETCHNTA", r1)
 }
 
@@ -410,7 +432,7 @@ func (amd64 *Amd64) PREFETCHNTA(r1 interface{}, comment ...string) {
 //
 //	CALL ·myFunction(SB)     // call Go function
 //	CALL ·_mulGeneric(SB)    // call fallback implementation
-func (amd64 *Amd64) CALL(target interface{}, comment ...string) {
+func (amd64 *Amd64) CALL(target Operand, comment ...string) {
 	amd64.writeOp(comment, "CALL", target)
 }
 
@@ -435,8 +457,8 @@ func (amd64 *Amd64) CALL(target interface{}, comment ...string) {
 //   - offset: byte offset from symbol start
 //   - width: size of the data in bytes (1, 2, 4, or 8)
 //   - value: the constant value
-func (amd64 *Amd64) DATA(symbol string, offset int, width int, value interface{}, comment ...string) {
-	amd64.writeOp(comment, "DATA", fmt.Sprintf("%s+%d(SB)/%d", symbol, offset, width), value)
+func (amd64 *Amd64) DATA(symbol string, offset int, width int, value Operand, comment ...string) {
+	amd64.writeOp(comment, "DATA", Raw(fmt.Sprintf("%s+%d(SB)/%d", symbol, offset, width)), value)
 }
 
 // GLOBL declares a global symbol with the specified size and flags.
@@ -460,7 +482,7 @@ func (amd64 *Amd64) DATA(symbol string, offset int, width int, value interface{}
 //   - flags: symbol attributes as string (e.g., "RODATA|NOPTR")
 //   - size: total size of the symbol in bytes
 func (amd64 *Amd64) GLOBL(symbol string, flags string, size int, comment ...string) {
-	amd64.writeOp(comment, "GLOBL", fmt.Sprintf("%s(SB)", symbol), flags, fmt.Sprintf("$%d", size))
+	amd64.writeOp(comment, "GLOBL", SymRef{Name: symbol}, Raw(flags), Raw(fmt.Sprintf("$%d", size)))
 }
 
 // NO_LOCAL_POINTERS is an assembly directive that indicates the function
@@ -484,7 +506,7 @@ func (amd64 *Amd64) NO_LOCAL_POINTERS() {
 //
 //	NEGQ r64
 //	NEGQ m64
-func (amd64 *Amd64) NEGQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) NEGQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "NEGQ", r1)
 }
 
@@ -495,7 +517,7 @@ func (amd64 *Amd64) NEGQ(r1 interface{}, comment ...string) {
 //
 //	NOTQ r64
 //	NOTQ m64
-func (amd64 *Amd64) NOTQ(r1 interface{}, comment ...string) {
+func (amd64 *Amd64) NOTQ(r1 Operand, comment ...string) {
 	amd64.writeOp(comment, "NOTQ", r1)
 }
 
@@ -509,7 +531,7 @@ func (amd64 *Amd64) NOTQ(r1 interface{}, comment ...string) {
 // Example:
 //
 //	LEAQ 8(AX)(BX*8), CX  // CX = AX + BX*8 + 8
-func (amd64 *Amd64) LEAQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) LEAQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "LEAQ", r1, r2)
 }
 
@@ -519,7 +541,7 @@ func (amd64 *Amd64) LEAQ(r1, r2 interface{}, comment ...string) {
 //
 //	ROLQ imm8, r64
 //	ROLQ CL, r64
-func (amd64 *Amd64) ROLQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) ROLQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "ROLQ", r1, r2)
 }
 
@@ -529,7 +551,7 @@ func (amd64 *Amd64) ROLQ(r1, r2 interface{}, comment ...string) {
 //
 //	RORQ imm8, r64
 //	RORQ CL, r64
-func (amd64 *Amd64) RORQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) RORQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "RORQ", r1, r2)
 }
 
@@ -539,6 +561,6 @@ func (amd64 *Amd64) RORQ(r1, r2 interface{}, comment ...string) {
 //
 //	SARQ imm8, r64
 //	SARQ CL, r64
-func (amd64 *Amd64) SARQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) SARQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "SARQ", r1, r2)
 }
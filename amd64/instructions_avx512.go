@@ -1,37 +1,35 @@
 package amd64
 
-import "fmt"
-
 // AVX 512 instructions
 // some of the documentation (in particular for AVX512 ops) is taken from https://github.com/mmcloughlin/avo
 
 // VPMULLD: Multiply Packed Signed Doubleword Integers and Store Low Result.
-func (amd64 *Amd64) VPMULLD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULLD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULLD", r1, r2, r3)
 }
 
 // VPMULLD_BCST: Multiply Packed Signed Doubleword Integers and Store Low Result (Broadcast).
-func (amd64 *Amd64) VPMULLD_BCST(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULLD_BCST(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULLD.BCST", r1, r2, r3)
 }
 
 // VPMULLQ: Multiply Packed Signed Quadword Integers and Store Low Result.
-func (amd64 *Amd64) VPMULLQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULLQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULLQ", r1, r2, r3)
 }
 
 // VPMULLQ_BCST: Multiply Packed Signed Quadword Integers and Store Low Result (Broadcast).
-func (amd64 *Amd64) VPMULLQ_BCST(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULLQ_BCST(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULLQ.BCST", r1, r2, r3)
 }
 
 // VMOVSHDUP: Move Packed Single-FP High and Duplicate
-func (amd64 *Amd64) VMOVSHDUP(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVSHDUP(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVSHDUP", r1, r2)
 }
 
 // VMOVSHDUPk: Move Packed Single-FP High and Duplicate
-func (amd64 *Amd64) VMOVSHDUPk(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVSHDUPk(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVSHDUP", r1, k, r2)
 }
 
@@ -51,7 +49,7 @@ func (amd64 *Amd64) VMOVSHDUPk(r1, k, r2 interface{}, comment ...string) {
 //	VPCMPUD imm8 m512 zmm k
 //	VPCMPUD imm8 zmm  zmm k k
 //	VPCMPUD imm8 zmm  zmm k
-func (amd64 *Amd64) VPCMPUD(imm8, r1, r2, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPCMPUD(imm8, r1, r2, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCMPUD", imm8, r1, r2, k)
 }
 
@@ -62,8 +60,8 @@ func (amd64 *Amd64) VPCMPUD(imm8, r1, r2, k interface{}, comment ...string) {
 // Forms:
 //
 //	VPCMPLTUD r1 r2 k
-func (amd64 *Amd64) VPCMPLTUD(r1, r2, k interface{}, comment ...string) {
-	amd64.writeOp(comment, "VPCMPUD", "$1", r1, r2, k)
+func (amd64 *Amd64) VPCMPLTUD(r1, r2, k Operand, comment ...string) {
+	amd64.writeOp(comment, "VPCMPUD", UImm8(1), r1, r2, k)
 }
 
 // VPMADD52HUQ: Packed Multiply of Unsigned 52-bit Unsigned Integers and Add High 52-bit Products to Quadword Accumulators.
@@ -82,93 +80,93 @@ func (amd64 *Amd64) VPCMPLTUD(r1, r2, k interface{}, comment ...string) {
 //	VPMADD52HUQ m512 zmm zmm
 //	VPMADD52HUQ zmm  zmm k zmm
 //	VPMADD52HUQ zmm  zmm zmm
-func (amd64 *Amd64) VPMADD52HUQ(r1, r2, r3 any, comment ...string) {
+func (amd64 *Amd64) VPMADD52HUQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMADD52HUQ", r1, r2, r3)
 }
 
 // VPMADD52LUQ: Packed Multiply of Unsigned 52-bit Integers and Add the Low 52-bit Products to Quadword Accumulators.
-func (amd64 *Amd64) VPMADD52LUQ(r1, r2, r3 any, comment ...string) {
+func (amd64 *Amd64) VPMADD52LUQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMADD52LUQ", r1, r2, r3)
 }
 
 // VPMADD52LUQ_BCST: Packed Multiply of Unsigned 52-bit Integers and Add Low 52-bit Products (Broadcast).
-func (amd64 *Amd64) VPMADD52LUQ_BCST(r1, r2, r3 any, comment ...string) {
+func (amd64 *Amd64) VPMADD52LUQ_BCST(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMADD52LUQ.BCST", r1, r2, r3)
 }
 
 // VPMADD52HUQ_BCST: Packed Multiply of Unsigned 52-bit Integers and Add High 52-bit Products (Broadcast).
-func (amd64 *Amd64) VPMADD52HUQ_BCST(r1, r2, r3 any, comment ...string) {
+func (amd64 *Amd64) VPMADD52HUQ_BCST(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMADD52HUQ.BCST", r1, r2, r3)
 }
 
 // VPBROADCASTQ: Broadcast Quadword Integer
-func (amd64 *Amd64) VPBROADCASTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPBROADCASTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPBROADCASTQ", r1, r2)
 }
 
 // VPBROADCASTD: Broadcast Doubleword Integer
-func (amd64 *Amd64) VPBROADCASTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPBROADCASTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPBROADCASTD", r1, r2)
 }
 
 // VPADDD: Add Packed Doubleword Integers
-func (amd64 *Amd64) VPADDD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPADDD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPADDD", r1, r2, r3)
 }
 
 // VPADDDk: Add Packed Doubleword Integers
-func (amd64 *Amd64) VPADDDk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPADDDk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPADDD", r1, r2, k, r3)
 }
 
 // VPTESTMD zmm  zmm k
-func (amd64 *Amd64) VPTESTMD(r1, r2, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPTESTMD(r1, r2, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPTESTMD", r1, r2, k)
 }
 
 // VPMADDWD zmm  zmm zmm
-func (amd64 *Amd64) VPMADDWD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMADDWD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMADDWD", r1, r2, r3)
 }
 
 // VPSUBD: Subtract Packed Doubleword Integers
-func (amd64 *Amd64) VPSUBD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSUBD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSUBD", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPSUBDk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPSUBDk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSUBD", r1, r2, k, r3)
 }
 
 // VPMINUD: Minimum of Packed Unsigned Doubleword Integers
-func (amd64 *Amd64) VPMINUD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMINUD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMINUD", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPMINUDk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPMINUDk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMINUD", r1, r2, k, r3)
 }
 
 // VPMINUQ: Minimum of Packed Unsigned Quadword Integers
-func (amd64 *Amd64) VPMINUQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMINUQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMINUQ", r1, r2, r3)
 }
 
 // VPSLLQ: Shift Packed Quadword Data Left Logical
-func (amd64 *Amd64) VPSLLQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPSLLD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLD", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPSLLDk(r1, r2, k, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLDk(r1, r2, k, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLD", r1, r2, k, r3)
 }
 
 // VPSUBQ: Subtract Packed Quadword Integers
-func (amd64 *Amd64) VPSUBQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSUBQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSUBQ", r1, r2, r3)
 }
 
@@ -179,7 +177,7 @@ func (amd64 *Amd64) VPSUBQ(r1, r2, r3 interface{}, comment ...string) {
 //
 //	VPSRAQ imm8, zmm, zmm
 //	VPSRAQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSRAQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRAQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRAQ", r1, r2, r3)
 }
 
@@ -189,22 +187,22 @@ func (amd64 *Amd64) VPSRAQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPANDNQ zmm, zmm, zmm
-func (amd64 *Amd64) VPANDNQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPANDNQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPANDNQ", r1, r2, r3)
 }
 
 // KNOTB: NOT 8-bit Mask Register
-func (amd64 *Amd64) KNOTB(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KNOTB(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KNOTB", r1, r2)
 }
 
 // VSHUFI64X2: Shuffle 128-Bit Packed Quadword Integer Values
-func (amd64 *Amd64) VSHUFI64X2(r1, r2, r3, r4 interface{}, comment ...string) {
+func (amd64 *Amd64) VSHUFI64X2(r1, r2, r3, r4 Operand, comment ...string) {
 	amd64.writeOp(comment, "VSHUFI64X2", r1, r2, r3, r4)
 }
 
 // VPERMQ: Permute Quadword Integers
-func (amd64 *Amd64) VPERMQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMQ", r1, r2, r3)
 }
 
@@ -216,7 +214,7 @@ func (amd64 *Amd64) VPERMQ(r1, r2, r3 interface{}, comment ...string) {
 //	VEXTRACTI32X8 imm8 zmm k ymm
 //	VEXTRACTI32X8 imm8 zmm m256
 //	VEXTRACTI32X8 imm8 zmm ymm
-func (amd64 *Amd64) VEXTRACTI32X8(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VEXTRACTI32X8(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VEXTRACTI32X8", imm8, r1, r2)
 }
 
@@ -236,7 +234,7 @@ func (amd64 *Amd64) VEXTRACTI32X8(imm8, r1, r2 interface{}, comment ...string) {
 //	VPBLENDMQ m512 zmm zmm
 //	VPBLENDMQ zmm  zmm k zmm
 //	VPBLENDMQ zmm  zmm zmm
-func (amd64 *Amd64) VPBLENDMQ(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPBLENDMQ(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPBLENDMQ", r1, r2, k, r3)
 }
 
@@ -248,7 +246,7 @@ func (amd64 *Amd64) VPBLENDMQ(r1, r2, r3, k interface{}, comment ...string) {
 //	VEXTRACTI64X4 imm8 zmm k ymm
 //	VEXTRACTI64X4 imm8 zmm m256
 //	VEXTRACTI64X4 imm8 zmm ymm
-func (amd64 *Amd64) VEXTRACTI64X4(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VEXTRACTI64X4(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VEXTRACTI64X4", imm8, r1, r2)
 }
 
@@ -268,7 +266,7 @@ func (amd64 *Amd64) VEXTRACTI64X4(imm8, r1, r2 interface{}, comment ...string) {
 //	VPSHRDQ imm8 m512 zmm zmm
 //	VPSHRDQ imm8 zmm  zmm k zmm
 //	VPSHRDQ imm8 zmm  zmm zmm
-func (amd64 *Amd64) VPSHRDQ(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHRDQ(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHRDQ", imm8, r1, r2, r3)
 }
 
@@ -288,42 +286,42 @@ func (amd64 *Amd64) VPSHRDQ(imm8, r1, r2, r3 interface{}, comment ...string) {
 //	VPBLENDMD m512 zmm zmm
 //	VPBLENDMD zmm  zmm k zmm
 //	VPBLENDMD zmm  zmm zmm
-func (amd64 *Amd64) VPBLENDMD(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPBLENDMD(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPBLENDMD", r1, r2, k, r3)
 }
 
 // VPBLENDD
-func (amd64 *Amd64) VPBLENDD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPBLENDD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPBLENDD", imm8, r1, r2, r3)
 }
 
 // VEXTRACTI64X2
-func (amd64 *Amd64) VEXTRACTI64X2(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VEXTRACTI64X2(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VEXTRACTI64X2", imm8, r1, r2)
 }
 
 // VPERMD: Permute Doubleword Integers
-func (amd64 *Amd64) VPERMD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMD", r1, r2, r3)
 }
 
 // VPERMD_BCST_Z: Permute Doubleword Integers (Broadcast, Zeroing Masking)
-func (amd64 *Amd64) VPERMD_BCST_Z(r1, r2, k, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMD_BCST_Z(r1, r2, k, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMD.BCST.Z", r1, r2, k, r3)
 }
 
 // KMOVW Move 16-bit Mask
-func (amd64 *Amd64) KMOVW(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KMOVW(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KMOVW", r1, r2)
 }
 
 // KMOVD Move 32-bit Mask
-func (amd64 *Amd64) KMOVD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KMOVD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KMOVD", r1, r2)
 }
 
 // KMOVQ Move 64-bit Mask
-func (amd64 *Amd64) KMOVQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KMOVQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KMOVQ", r1, r2)
 }
 
@@ -333,67 +331,67 @@ func (amd64 *Amd64) KMOVQ(r1, r2 interface{}, comment ...string) {
 //
 //	VPINSRQ imm8 m64 xmm xmm
 //	VPINSRQ imm8 r64 xmm xmm
-func (amd64 *Amd64) VPINSRQ(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPINSRQ(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPINSRQ", imm8, r1, r2, r3)
 }
 
 // VPINSRD: Insert Doubleword.
-func (amd64 *Amd64) VPINSRD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPINSRD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPINSRD", imm8, r1, r2, r3)
 }
 
 // KSHIFTLW Shift 16-bit Mask Left
-func (amd64 *Amd64) KSHIFTLW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTLW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTLW", r1, r2, r3)
 }
 
 // KADDW Add 16-bit Masks
-func (amd64 *Amd64) KADDW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KADDW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KADDW", r1, r2, r3)
 }
 
 // VXORPS Bitwise Logical XOR
-func (amd64 *Amd64) VXORPS(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VXORPS(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VXORPS", r1, r2, r3)
 }
 
 // VPXORQ Bitwise Logical Exclusive OR of Packed Quadword Integers
-func (amd64 *Amd64) VPXORQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPXORQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPXORQ", r1, r2, r3)
 }
 
 // VPORQ: Bitwise Logical OR of Packed Quadword Integers
-func (amd64 *Amd64) VPORQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPORQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPORQ", r1, r2, r3)
 }
 
 // VPERMT2Q: Full Permute of Quadwords From Two Tables Overwriting a Table
-func (amd64 *Amd64) VPERMT2Q(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMT2Q(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMT2Q", r1, r2, r3)
 }
 
 // VPMOVQ2M: Move Signs of Packed Quadword Integers to Mask Register
-func (amd64 *Amd64) VPMOVQ2M(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMOVQ2M(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMOVQ2M", r1, r2)
 }
 
 // VMOVDQA32: Move Aligned Doubleword Values
-func (amd64 *Amd64) VMOVDQA32(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQA32(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQA32", r1, r2)
 }
 
 // VPSRLD: Shift Packed Doubleword Data Right Logical.
-func (amd64 *Amd64) VPSRLD(imm8, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLD(imm8, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLD", imm8, r2, r3)
 }
 
 // VPSHUFLW: Shuffle Packed Low Words.
-func (amd64 *Amd64) VPSHUFLW(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHUFLW(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHUFLW", imm8, r1, r2)
 }
 
 // VPSHUFHW: Shuffle Packed High Words.
-func (amd64 *Amd64) VPSHUFHW(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHUFHW(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHUFHW", imm8, r1, r2)
 }
 
@@ -413,32 +411,32 @@ func (amd64 *Amd64) VPSHUFHW(imm8, r1, r2 interface{}, comment ...string) {
 //	VPMOVDW zmm k ymm
 //	VPMOVDW zmm m256
 //	VPMOVDW zmm ymm
-func (amd64 *Amd64) VPMOVDW(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMOVDW(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMOVDW", r1, r2)
 }
 
 // VMOVDQA64 Move Aligned Quadword Values
-func (amd64 *Amd64) VMOVDQA64(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQA64(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQA64", r1, r2)
 }
 
 // VMOVDQA64_Z Move Aligned Quadword Values  (Zeroing Masking).
-func (amd64 *Amd64) VMOVDQA64_Z(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQA64_Z(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQA64.Z", r1, k, r2)
 }
 
 // VMOVDQA32_Z Move Aligned Quadword Values  (Zeroing Masking).
-func (amd64 *Amd64) VMOVDQA32_Z(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQA32_Z(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQA32.Z", r1, k, r2)
 }
 
 // VPMOVQD: Down Convert Packed Quadword Values to Doubleword Values with Truncation.
-func (amd64 *Amd64) VPMOVQD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMOVQD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMOVQD", r1, r2)
 }
 
 // VPMOVZXDQ Move Packed Doubleword Integers to Quadword Integers
-func (amd64 *Amd64) VPMOVZXDQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMOVZXDQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMOVZXDQ", r1, r2)
 }
 
@@ -478,7 +476,7 @@ func (amd64 *Amd64) VPMOVZXWD(r1, r2 VectorRegister, comment ...string) {
 //	VPSHUFD imm8 m512 zmm
 //	VPSHUFD imm8 zmm  k zmm
 //	VPSHUFD imm8 zmm  zmm
-func (amd64 *Amd64) VPSHUFD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHUFD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHUFD", imm8, r1, r2)
 }
 
@@ -498,7 +496,7 @@ func (amd64 *Amd64) VPSHUFD(imm8, r1, r2 interface{}, comment ...string) {
 //	VSHUFPD imm8 m512 zmm zmm
 //	VSHUFPD imm8 zmm  zmm k zmm
 //	VSHUFPD imm8 zmm  zmm zmm
-func (amd64 *Amd64) VSHUFPD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VSHUFPD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VSHUFPD", imm8, r1, r2, r3)
 }
 
@@ -554,7 +552,7 @@ func (amd64 *Amd64) VPUNPCKHDQ(r1, r2, r3 VectorRegister, comment ...string) {
 //	VSHUFF64X2 imm8 m512 zmm zmm
 //	VSHUFF64X2 imm8 zmm  zmm k zmm
 //	VSHUFF64X2 imm8 zmm  zmm zmm
-func (amd64 *Amd64) VSHUFF64X2(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VSHUFF64X2(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VSHUFF64X2", imm8, r1, r2, r3)
 }
 
@@ -570,7 +568,7 @@ func (amd64 *Amd64) VSHUFF64X2(imm8, r1, r2, r3 interface{}, comment ...string)
 //	VSHUFF32X4 imm8 m512 zmm zmm
 //	VSHUFF32X4 imm8 zmm  zmm k zmm
 //	VSHUFF32X4 imm8 zmm  zmm zmm
-func (amd64 *Amd64) VSHUFF32X4(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VSHUFF32X4(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VSHUFF32X4", imm8, r1, r2, r3)
 }
 
@@ -582,7 +580,7 @@ func (amd64 *Amd64) VSHUFF32X4(imm8, r1, r2, r3 interface{}, comment ...string)
 //	VINSERTI64X4 imm8 m256 zmm zmm
 //	VINSERTI64X4 imm8 ymm  zmm k zmm
 //	VINSERTI64X4 imm8 ymm  zmm zmm
-func (amd64 *Amd64) VINSERTI64X4(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VINSERTI64X4(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VINSERTI64X4", imm8, r1, r2, r3)
 }
 
@@ -598,29 +596,29 @@ func (amd64 *Amd64) VINSERTI64X4(imm8, r1, r2, r3 interface{}, comment ...string
 //	VINSERTI64X2 imm8 m128 zmm zmm
 //	VINSERTI64X2 imm8 xmm  zmm k zmm
 //	VINSERTI64X2 imm8 xmm  zmm zmm
-func (amd64 *Amd64) VINSERTI64X2(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VINSERTI64X2(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VINSERTI64X2", imm8, r1, r2, r3)
 }
 
 // VMOVDQU32: Move Unaligned Doubleword Values
-func (amd64 *Amd64) VMOVDQU32(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQU32(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQU32", r1, r2)
 }
 
 // VMOVDQU64 Move Unaligned Quadword Values
-func (amd64 *Amd64) VMOVDQU64(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQU64(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQU64", r1, r2)
 }
 
 // VPGATHERDD: Gather Packed Doubleword Values Using Signed Doubleword Indices.
 // example: VPGATHERDD  8(AX)(Z18*4), K7, Z6
 func (amd64 *Amd64) VPGATHERDD(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, res VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPGATHERDD", fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale), mask, res)
+	amd64.writeOp(comment, "VPGATHERDD", Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset}, mask, res)
 }
 
 // VPSCATTERDD: Scatter Packed Doubleword Values with Signed Doubleword Indices.
 func (amd64 *Amd64) VPSCATTERDD(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, src VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPSCATTERDD", src, mask, fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale))
+	amd64.writeOp(comment, "VPSCATTERDD", src, mask, Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset})
 }
 
 // VPERMI2Q: Full Permute of Quadwords From Two Tables Overwriting the Index.
@@ -639,17 +637,17 @@ func (amd64 *Amd64) VPSCATTERDD(baseAddrOffset int, baseAddr Register, indices V
 //	VPERMI2Q m512 zmm zmm
 //	VPERMI2Q zmm  zmm k zmm
 //	VPERMI2Q zmm  zmm zmm
-func (amd64 *Amd64) VPERMI2Q(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMI2Q(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMI2Q", r1, r2, r3)
 }
 
 // VPERMI2D: Full Permute of Doublewords From Two Tables Overwriting the Index.
-func (amd64 *Amd64) VPERMI2D(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMI2D(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMI2D", r1, r2, r3)
 }
 
 // VMOVDQU64 Move Unaligned Quadword Values
-func (amd64 *Amd64) VMOVDQU64k(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVDQU64k(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVDQU64", r1, k, r2)
 }
 
@@ -669,61 +667,61 @@ func (amd64 *Amd64) VMOVDQU64k(r1, k, r2 interface{}, comment ...string) {
 //	VPTERNLOGD imm8 m512 zmm zmm
 //	VPTERNLOGD imm8 zmm  zmm k zmm
 //	VPTERNLOGD imm8 zmm  zmm zmm
-func (amd64 *Amd64) VPTERNLOGD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPTERNLOGD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPTERNLOGD", imm8, r1, r2, r3)
 }
 
 // VPADDQ Add Packed Quadword Integers
-func (amd64 *Amd64) VPADDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPADDQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPADDQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPADDQk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPADDQk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPADDQ", r1, r2, k, r3)
 }
 
 // VPMULUDQ Multiply Packed Unsigned Doubleword Integers
-func (amd64 *Amd64) VPMULUDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULUDQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULUDQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPMULUDQk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULUDQk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULUDQ", r1, r2, k, r3)
 }
 
 // VPMULUDQ_BCST Multiply Packed Unsigned Doubleword Integers (Broadcast).
-func (amd64 *Amd64) VPMULUDQ_BCST(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMULUDQ_BCST(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMULUDQ.BCST", r1, r2, r3)
 }
 
 // VPANDQ Bitwise Logical AND of Packed Quadword Integers
-func (amd64 *Amd64) VPANDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPANDQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPANDQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPANDD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPANDD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPANDD", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPANDDk(r1, r2, k, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPANDDk(r1, r2, k, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPANDD", r1, r2, k, r3)
 }
 
-func (amd64 *Amd64) VPANDDkz(r1, r2, k, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPANDDkz(r1, r2, k, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPANDD.Z", r1, r2, k, r3)
 }
 
 // VPSRLQ Shift Packed Quadword Data Right Logical
-func (amd64 *Amd64) VPSRLQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLQ", r1, r2, r3)
 }
 
-func (amd64 *Amd64) VPSRLQk(r1, r2, r3, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLQk(r1, r2, r3, k Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLQ", r1, r2, k, r3)
 }
 
 // VPEXTRQ Extract Quadword
-func (amd64 *Amd64) VPEXTRQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPEXTRQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPEXTRQ", r1, r2, r3)
 }
 
@@ -744,18 +742,13 @@ func (amd64 *Amd64) VPEXTRQ(r1, r2, r3 interface{}, comment ...string) {
 // Example:
 //
 //	VALIGND $0, Z15, Z11, Z11  // effectively copies Z11 elements shifted by Z15 position
-func (amd64 *Amd64) VALIGND(r1, r2, r3, r4 interface{}, r5 ...interface{}) {
+func (amd64 *Amd64) VALIGND(r1, r2, r3, r4 Operand, r5 ...Operand) {
 	if len(r5) == 0 {
 		// 4-operand form: imm8, src2, src1, dst
 		amd64.writeOp(nil, "VALIGND", r1, r2, r3, r4)
 	} else {
 		// 5-operand form: imm8, src2, src1, k, dst
-		comment, _ := r5[0].(string)
-		var comments []string
-		if comment != "" {
-			comments = []string{comment}
-		}
-		amd64.writeOp(comments, "VALIGND", r1, r2, r3, r4, r5[0])
+		amd64.writeOp(nil, "VALIGND", r1, r2, r3, r4, r5[0])
 	}
 }
 
@@ -765,7 +758,7 @@ func (amd64 *Amd64) VALIGND(r1, r2, r3, r4 interface{}, r5 ...interface{}) {
 // Forms:
 //
 //	VALIGND imm8, zmm, zmm, k, zmm
-func (amd64 *Amd64) VALIGNDk(imm8, src2, src1, k, dst interface{}, comment ...string) {
+func (amd64 *Amd64) VALIGNDk(imm8, src2, src1, k, dst Operand, comment ...string) {
 	amd64.writeOp(comment, "VALIGND", imm8, src2, src1, k, dst)
 }
 
@@ -775,7 +768,7 @@ func (amd64 *Amd64) VALIGNDk(imm8, src2, src1, k, dst interface{}, comment ...st
 // Forms:
 //
 //	VALIGND.Z imm8, zmm, zmm, k, zmm
-func (amd64 *Amd64) VALIGND_Z(imm8, src2, src1, k, dst interface{}, comment ...string) {
+func (amd64 *Amd64) VALIGND_Z(imm8, src2, src1, k, dst Operand, comment ...string) {
 	amd64.writeOp(comment, "VALIGND.Z", imm8, src2, src1, k, dst)
 }
 
@@ -790,22 +783,22 @@ func (amd64 *Amd64) VALIGND_Z(imm8, src2, src1, k, dst interface{}, comment ...s
 //
 //	temp[1023:0] = src2[511:0]:src1[511:0]
 //	dst[511:0] = temp[imm8*64+511:imm8*64]
-func (amd64 *Amd64) VALIGNQ(imm8, src2, src1, dst interface{}, comment ...string) {
+func (amd64 *Amd64) VALIGNQ(imm8, src2, src1, dst Operand, comment ...string) {
 	amd64.writeOp(comment, "VALIGNQ", imm8, src2, src1, dst)
 }
 
 // VMOVQ Move Quadword
-func (amd64 *Amd64) VMOVQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVQ", r1, r2)
 }
 
 // VMOVD Move Doubleword
-func (amd64 *Amd64) VMOVD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VMOVD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VMOVD", r1, r2)
 }
 
 // VPCMPEQB Compare Packed Byte Data for Equality
-func (amd64 *Amd64) VPCMPEQB(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPCMPEQB(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCMPEQB", r1, r2, r3)
 }
 
@@ -815,42 +808,42 @@ func (amd64 *Amd64) VPCMPEQB(r1, r2, r3 interface{}, comment ...string) {
 //
 //	PEXTRQ imm8 xmm m64
 //	PEXTRQ imm8 xmm r64
-func (amd64 *Amd64) PEXTRQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) PEXTRQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "PEXTRQ", imm8, r1, r2)
 }
 
 // PEXTRD: Extract Doubleword.
-func (amd64 *Amd64) PEXTRD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) PEXTRD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "PEXTRD", imm8, r1, r2)
 }
 
 // KORTESTQ: OR 64-bit Masks and Set Flags
-func (amd64 *Amd64) KORTESTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KORTESTQ(r1, r2 Operand, comment ...string) {
         amd64.writeOp(comment, "KORTESTQ", r1, r2)
 }
 
 // VPCMPUQ: Compare Packed Unsigned Quadword Values.
-func (amd64 *Amd64) VPCMPUQ(imm8, r1, r2, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPCMPUQ(imm8, r1, r2, k Operand, comment ...string) {
         amd64.writeOp(comment, "VPCMPUQ", imm8, r1, r2, k)
 }
 
 // VPTESTMQ: Packed Quadword Test Mask
-func (amd64 *Amd64) VPTESTMQ(r1, r2, k interface{}, comment ...string) {
+func (amd64 *Amd64) VPTESTMQ(r1, r2, k Operand, comment ...string) {
         amd64.writeOp(comment, "VPTESTMQ", r1, r2, k)
 }
 
 // VPUNPCKLQDQ: Unpack and Interleave Low-Order Quadwords
-func (amd64 *Amd64) VPUNPCKLQDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPUNPCKLQDQ(r1, r2, r3 Operand, comment ...string) {
         amd64.writeOp(comment, "VPUNPCKLQDQ", r1, r2, r3)
 }
 
 // VPUNPCKHQDQ: Unpack and Interleave High-Order Quadwords
-func (amd64 *Amd64) VPUNPCKHQDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPUNPCKHQDQ(r1, r2, r3 Operand, comment ...string) {
         amd64.writeOp(comment, "VPUNPCKHQDQ", r1, r2, r3)
 }
 
 // KMOVB Move 8-bit Mask
-func (amd64 *Amd64) KMOVB(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KMOVB(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KMOVB", r1, r2)
 }
 
@@ -863,7 +856,7 @@ func (amd64 *Amd64) KMOVB(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KNOTW k, k
-func (amd64 *Amd64) KNOTW(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KNOTW(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KNOTW", r1, r2)
 }
 
@@ -872,7 +865,7 @@ func (amd64 *Amd64) KNOTW(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KNOTD k, k
-func (amd64 *Amd64) KNOTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KNOTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KNOTD", r1, r2)
 }
 
@@ -881,7 +874,7 @@ func (amd64 *Amd64) KNOTD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KNOTQ k, k
-func (amd64 *Amd64) KNOTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KNOTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KNOTQ", r1, r2)
 }
 
@@ -890,7 +883,7 @@ func (amd64 *Amd64) KNOTQ(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KANDW k, k, k
-func (amd64 *Amd64) KANDW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KANDW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KANDW", r1, r2, r3)
 }
 
@@ -899,7 +892,7 @@ func (amd64 *Amd64) KANDW(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KANDD k, k, k
-func (amd64 *Amd64) KANDD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KANDD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KANDD", r1, r2, r3)
 }
 
@@ -908,7 +901,7 @@ func (amd64 *Amd64) KANDD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KANDQ k, k, k
-func (amd64 *Amd64) KANDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KANDQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KANDQ", r1, r2, r3)
 }
 
@@ -917,7 +910,7 @@ func (amd64 *Amd64) KANDQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KORW k, k, k
-func (amd64 *Amd64) KORW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KORW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KORW", r1, r2, r3)
 }
 
@@ -926,7 +919,7 @@ func (amd64 *Amd64) KORW(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KORD k, k, k
-func (amd64 *Amd64) KORD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KORD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KORD", r1, r2, r3)
 }
 
@@ -935,7 +928,7 @@ func (amd64 *Amd64) KORD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KORQ k, k, k
-func (amd64 *Amd64) KORQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KORQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KORQ", r1, r2, r3)
 }
 
@@ -944,7 +937,7 @@ func (amd64 *Amd64) KORQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KXORW k, k, k
-func (amd64 *Amd64) KXORW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KXORW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KXORW", r1, r2, r3)
 }
 
@@ -953,7 +946,7 @@ func (amd64 *Amd64) KXORW(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KXORD k, k, k
-func (amd64 *Amd64) KXORD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KXORD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KXORD", r1, r2, r3)
 }
 
@@ -962,7 +955,7 @@ func (amd64 *Amd64) KXORD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KXORQ k, k, k
-func (amd64 *Amd64) KXORQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KXORQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KXORQ", r1, r2, r3)
 }
 
@@ -971,7 +964,7 @@ func (amd64 *Amd64) KXORQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KSHIFTRW imm8, k, k
-func (amd64 *Amd64) KSHIFTRW(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTRW(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTRW", imm8, r1, r2)
 }
 
@@ -980,7 +973,7 @@ func (amd64 *Amd64) KSHIFTRW(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KSHIFTRD imm8, k, k
-func (amd64 *Amd64) KSHIFTRD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTRD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTRD", imm8, r1, r2)
 }
 
@@ -989,7 +982,7 @@ func (amd64 *Amd64) KSHIFTRD(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KSHIFTRQ imm8, k, k
-func (amd64 *Amd64) KSHIFTRQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTRQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTRQ", imm8, r1, r2)
 }
 
@@ -998,7 +991,7 @@ func (amd64 *Amd64) KSHIFTRQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KSHIFTLD imm8, k, k
-func (amd64 *Amd64) KSHIFTLD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTLD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTLD", imm8, r1, r2)
 }
 
@@ -1007,7 +1000,7 @@ func (amd64 *Amd64) KSHIFTLD(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KSHIFTLQ imm8, k, k
-func (amd64 *Amd64) KSHIFTLQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KSHIFTLQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KSHIFTLQ", imm8, r1, r2)
 }
 
@@ -1016,7 +1009,7 @@ func (amd64 *Amd64) KSHIFTLQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KADDB k, k, k
-func (amd64 *Amd64) KADDB(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KADDB(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KADDB", r1, r2, r3)
 }
 
@@ -1025,7 +1018,7 @@ func (amd64 *Amd64) KADDB(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KADDD k, k, k
-func (amd64 *Amd64) KADDD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KADDD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KADDD", r1, r2, r3)
 }
 
@@ -1034,7 +1027,7 @@ func (amd64 *Amd64) KADDD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KADDQ k, k, k
-func (amd64 *Amd64) KADDQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) KADDQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "KADDQ", r1, r2, r3)
 }
 
@@ -1043,7 +1036,7 @@ func (amd64 *Amd64) KADDQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	KTESTB k, k
-func (amd64 *Amd64) KTESTB(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KTESTB(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KTESTB", r1, r2)
 }
 
@@ -1052,7 +1045,7 @@ func (amd64 *Amd64) KTESTB(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KTESTW k, k
-func (amd64 *Amd64) KTESTW(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KTESTW(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KTESTW", r1, r2)
 }
 
@@ -1061,7 +1054,7 @@ func (amd64 *Amd64) KTESTW(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KTESTD k, k
-func (amd64 *Amd64) KTESTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KTESTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KTESTD", r1, r2)
 }
 
@@ -1070,17 +1063,17 @@ func (amd64 *Amd64) KTESTD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	KTESTQ k, k
-func (amd64 *Amd64) KTESTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KTESTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KTESTQ", r1, r2)
 }
 
 // KORTESTW: OR 16-bit Masks and Set Flags
-func (amd64 *Amd64) KORTESTW(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KORTESTW(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KORTESTW", r1, r2)
 }
 
 // KORTESTD: OR 32-bit Masks and Set Flags
-func (amd64 *Amd64) KORTESTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) KORTESTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "KORTESTD", r1, r2)
 }
 
@@ -1094,7 +1087,7 @@ func (amd64 *Amd64) KORTESTD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSLLDQ imm8, zmm, zmm
-func (amd64 *Amd64) VPSLLDQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLDQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLDQ", imm8, r1, r2)
 }
 
@@ -1103,7 +1096,7 @@ func (amd64 *Amd64) VPSLLDQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSRLDQ imm8, zmm, zmm
-func (amd64 *Amd64) VPSRLDQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLDQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLDQ", imm8, r1, r2)
 }
 
@@ -1113,7 +1106,7 @@ func (amd64 *Amd64) VPSRLDQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSLLVD zmm, zmm, zmm
-func (amd64 *Amd64) VPSLLVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLVD", r1, r2, r3)
 }
 
@@ -1122,7 +1115,7 @@ func (amd64 *Amd64) VPSLLVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSLLVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSLLVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSLLVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSLLVQ", r1, r2, r3)
 }
 
@@ -1131,7 +1124,7 @@ func (amd64 *Amd64) VPSLLVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSRLVD zmm, zmm, zmm
-func (amd64 *Amd64) VPSRLVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLVD", r1, r2, r3)
 }
 
@@ -1140,7 +1133,7 @@ func (amd64 *Amd64) VPSRLVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSRLVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSRLVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRLVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRLVQ", r1, r2, r3)
 }
 
@@ -1149,7 +1142,7 @@ func (amd64 *Amd64) VPSRLVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSRAVD zmm, zmm, zmm
-func (amd64 *Amd64) VPSRAVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRAVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRAVD", r1, r2, r3)
 }
 
@@ -1158,7 +1151,7 @@ func (amd64 *Amd64) VPSRAVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSRAVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSRAVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSRAVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSRAVQ", r1, r2, r3)
 }
 
@@ -1167,7 +1160,7 @@ func (amd64 *Amd64) VPSRAVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPROLD imm8, zmm, zmm
-func (amd64 *Amd64) VPROLD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPROLD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPROLD", imm8, r1, r2)
 }
 
@@ -1176,7 +1169,7 @@ func (amd64 *Amd64) VPROLD(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPROLQ imm8, zmm, zmm
-func (amd64 *Amd64) VPROLQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPROLQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPROLQ", imm8, r1, r2)
 }
 
@@ -1185,7 +1178,7 @@ func (amd64 *Amd64) VPROLQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPRORD imm8, zmm, zmm
-func (amd64 *Amd64) VPRORD(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPRORD(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPRORD", imm8, r1, r2)
 }
 
@@ -1194,7 +1187,7 @@ func (amd64 *Amd64) VPRORD(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPRORQ imm8, zmm, zmm
-func (amd64 *Amd64) VPRORQ(imm8, r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPRORQ(imm8, r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPRORQ", imm8, r1, r2)
 }
 
@@ -1203,7 +1196,7 @@ func (amd64 *Amd64) VPRORQ(imm8, r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPROLVD zmm, zmm, zmm
-func (amd64 *Amd64) VPROLVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPROLVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPROLVD", r1, r2, r3)
 }
 
@@ -1212,7 +1205,7 @@ func (amd64 *Amd64) VPROLVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPROLVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPROLVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPROLVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPROLVQ", r1, r2, r3)
 }
 
@@ -1221,7 +1214,7 @@ func (amd64 *Amd64) VPROLVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPRORVD zmm, zmm, zmm
-func (amd64 *Amd64) VPRORVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPRORVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPRORVD", r1, r2, r3)
 }
 
@@ -1230,7 +1223,7 @@ func (amd64 *Amd64) VPRORVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPRORVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPRORVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPRORVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPRORVQ", r1, r2, r3)
 }
 
@@ -1243,7 +1236,7 @@ func (amd64 *Amd64) VPRORVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMAXUD zmm, zmm, zmm
-func (amd64 *Amd64) VPMAXUD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMAXUD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMAXUD", r1, r2, r3)
 }
 
@@ -1252,7 +1245,7 @@ func (amd64 *Amd64) VPMAXUD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMAXUQ zmm, zmm, zmm
-func (amd64 *Amd64) VPMAXUQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMAXUQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMAXUQ", r1, r2, r3)
 }
 
@@ -1261,7 +1254,7 @@ func (amd64 *Amd64) VPMAXUQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMAXSD zmm, zmm, zmm
-func (amd64 *Amd64) VPMAXSD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMAXSD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMAXSD", r1, r2, r3)
 }
 
@@ -1270,7 +1263,7 @@ func (amd64 *Amd64) VPMAXSD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMAXSQ zmm, zmm, zmm
-func (amd64 *Amd64) VPMAXSQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMAXSQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMAXSQ", r1, r2, r3)
 }
 
@@ -1279,7 +1272,7 @@ func (amd64 *Amd64) VPMAXSQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMINSD zmm, zmm, zmm
-func (amd64 *Amd64) VPMINSD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMINSD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMINSD", r1, r2, r3)
 }
 
@@ -1288,7 +1281,7 @@ func (amd64 *Amd64) VPMINSD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPMINSQ zmm, zmm, zmm
-func (amd64 *Amd64) VPMINSQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPMINSQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPMINSQ", r1, r2, r3)
 }
 
@@ -1297,7 +1290,7 @@ func (amd64 *Amd64) VPMINSQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPABSD zmm, zmm
-func (amd64 *Amd64) VPABSD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPABSD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPABSD", r1, r2)
 }
 
@@ -1306,7 +1299,7 @@ func (amd64 *Amd64) VPABSD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPABSQ zmm, zmm
-func (amd64 *Amd64) VPABSQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPABSQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPABSQ", r1, r2)
 }
 
@@ -1319,7 +1312,7 @@ func (amd64 *Amd64) VPABSQ(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VSHUFI32X4 imm8, zmm, zmm, zmm
-func (amd64 *Amd64) VSHUFI32X4(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VSHUFI32X4(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VSHUFI32X4", imm8, r1, r2, r3)
 }
 
@@ -1328,7 +1321,7 @@ func (amd64 *Amd64) VSHUFI32X4(imm8, r1, r2, r3 interface{}, comment ...string)
 // Forms:
 //
 //	VPERMT2D zmm, zmm, zmm
-func (amd64 *Amd64) VPERMT2D(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMT2D(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMT2D", r1, r2, r3)
 }
 
@@ -1337,7 +1330,7 @@ func (amd64 *Amd64) VPERMT2D(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPERMW zmm, zmm, zmm
-func (amd64 *Amd64) VPERMW(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPERMW(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPERMW", r1, r2, r3)
 }
 
@@ -1347,7 +1340,7 @@ func (amd64 *Amd64) VPERMW(r1, r2, r3 interface{}, comment ...string) {
 //
 //	VPCOMPRESSD zmm, k, zmm
 //	VPCOMPRESSD zmm, k, m512
-func (amd64 *Amd64) VPCOMPRESSD(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPCOMPRESSD(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCOMPRESSD", r1, k, r2)
 }
 
@@ -1357,7 +1350,7 @@ func (amd64 *Amd64) VPCOMPRESSD(r1, k, r2 interface{}, comment ...string) {
 //
 //	VPCOMPRESSQ zmm, k, zmm
 //	VPCOMPRESSQ zmm, k, m512
-func (amd64 *Amd64) VPCOMPRESSQ(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPCOMPRESSQ(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCOMPRESSQ", r1, k, r2)
 }
 
@@ -1367,7 +1360,7 @@ func (amd64 *Amd64) VPCOMPRESSQ(r1, k, r2 interface{}, comment ...string) {
 //
 //	VPEXPANDD zmm, k, zmm
 //	VPEXPANDD m512, k, zmm
-func (amd64 *Amd64) VPEXPANDD(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPEXPANDD(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPEXPANDD", r1, k, r2)
 }
 
@@ -1377,7 +1370,7 @@ func (amd64 *Amd64) VPEXPANDD(r1, k, r2 interface{}, comment ...string) {
 //
 //	VPEXPANDQ zmm, k, zmm
 //	VPEXPANDQ m512, k, zmm
-func (amd64 *Amd64) VPEXPANDQ(r1, k, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPEXPANDQ(r1, k, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPEXPANDQ", r1, k, r2)
 }
 
@@ -1392,7 +1385,7 @@ func (amd64 *Amd64) VPEXPANDQ(r1, k, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPCONFLICTD zmm, zmm
-func (amd64 *Amd64) VPCONFLICTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPCONFLICTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCONFLICTD", r1, r2)
 }
 
@@ -1401,7 +1394,7 @@ func (amd64 *Amd64) VPCONFLICTD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPCONFLICTQ zmm, zmm
-func (amd64 *Amd64) VPCONFLICTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPCONFLICTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPCONFLICTQ", r1, r2)
 }
 
@@ -1410,7 +1403,7 @@ func (amd64 *Amd64) VPCONFLICTQ(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPLZCNTD zmm, zmm
-func (amd64 *Amd64) VPLZCNTD(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPLZCNTD(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPLZCNTD", r1, r2)
 }
 
@@ -1419,7 +1412,7 @@ func (amd64 *Amd64) VPLZCNTD(r1, r2 interface{}, comment ...string) {
 // Forms:
 //
 //	VPLZCNTQ zmm, zmm
-func (amd64 *Amd64) VPLZCNTQ(r1, r2 interface{}, comment ...string) {
+func (amd64 *Amd64) VPLZCNTQ(r1, r2 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPLZCNTQ", r1, r2)
 }
 
@@ -1440,7 +1433,7 @@ func (amd64 *Amd64) VPLZCNTQ(r1, r2 interface{}, comment ...string) {
 //	for each quadword element i:
 //	    temp = (src1[i] << 64) | src2[i]  // 128-bit concatenation
 //	    dst[i] = (temp << count)[127:64]   // extract upper 64 bits after shift
-func (amd64 *Amd64) VPSHLDQ(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHLDQ(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHLDQ", imm8, r1, r2, r3)
 }
 
@@ -1449,7 +1442,7 @@ func (amd64 *Amd64) VPSHLDQ(imm8, r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHLDD imm8, zmm, zmm, zmm
-func (amd64 *Amd64) VPSHLDD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHLDD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHLDD", imm8, r1, r2, r3)
 }
 
@@ -1458,7 +1451,7 @@ func (amd64 *Amd64) VPSHLDD(imm8, r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHRDD imm8, zmm, zmm, zmm
-func (amd64 *Amd64) VPSHRDD(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHRDD(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHRDD", imm8, r1, r2, r3)
 }
 
@@ -1467,7 +1460,7 @@ func (amd64 *Amd64) VPSHRDD(imm8, r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHLDVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSHLDVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHLDVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHLDVQ", r1, r2, r3)
 }
 
@@ -1476,7 +1469,7 @@ func (amd64 *Amd64) VPSHLDVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHLDVD zmm, zmm, zmm
-func (amd64 *Amd64) VPSHLDVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHLDVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHLDVD", r1, r2, r3)
 }
 
@@ -1485,7 +1478,7 @@ func (amd64 *Amd64) VPSHLDVD(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHRDVQ zmm, zmm, zmm
-func (amd64 *Amd64) VPSHRDVQ(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHRDVQ(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHRDVQ", r1, r2, r3)
 }
 
@@ -1494,7 +1487,7 @@ func (amd64 *Amd64) VPSHRDVQ(r1, r2, r3 interface{}, comment ...string) {
 // Forms:
 //
 //	VPSHRDVD zmm, zmm, zmm
-func (amd64 *Amd64) VPSHRDVD(r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPSHRDVD(r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPSHRDVD", r1, r2, r3)
 }
 
@@ -1508,7 +1501,7 @@ func (amd64 *Amd64) VPSHRDVD(r1, r2, r3 interface{}, comment ...string) {
 //
 //	VPGATHERDQ baseOffset(base)(index*scale), k, dst
 func (amd64 *Amd64) VPGATHERDQ(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, res VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPGATHERDQ", fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale), mask, res)
+	amd64.writeOp(comment, "VPGATHERDQ", Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset}, mask, res)
 }
 
 // VPGATHERQD gathers packed doublewords using signed qword indices.
@@ -1517,7 +1510,7 @@ func (amd64 *Amd64) VPGATHERDQ(baseAddrOffset int, baseAddr Register, indices Ve
 //
 //	VPGATHERQD baseOffset(base)(index*scale), k, dst
 func (amd64 *Amd64) VPGATHERQD(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, res VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPGATHERQD", fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale), mask, res)
+	amd64.writeOp(comment, "VPGATHERQD", Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset}, mask, res)
 }
 
 // VPGATHERQQ gathers packed quadwords using signed qword indices.
@@ -1526,7 +1519,7 @@ func (amd64 *Amd64) VPGATHERQD(baseAddrOffset int, baseAddr Register, indices Ve
 //
 //	VPGATHERQQ baseOffset(base)(index*scale), k, dst
 func (amd64 *Amd64) VPGATHERQQ(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, res VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPGATHERQQ", fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale), mask, res)
+	amd64.writeOp(comment, "VPGATHERQQ", Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset}, mask, res)
 }
 
 // VPSCATTERDQ scatters packed quadwords using signed dword indices.
@@ -1535,7 +1528,7 @@ func (amd64 *Amd64) VPGATHERQQ(baseAddrOffset int, baseAddr Register, indices Ve
 //
 //	VPSCATTERDQ src, k, baseOffset(base)(index*scale)
 func (amd64 *Amd64) VPSCATTERDQ(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, src VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPSCATTERDQ", src, mask, fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale))
+	amd64.writeOp(comment, "VPSCATTERDQ", src, mask, Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset})
 }
 
 // VPSCATTERQD scatters packed doublewords using signed qword indices.
@@ -1544,7 +1537,7 @@ func (amd64 *Amd64) VPSCATTERDQ(baseAddrOffset int, baseAddr Register, indices V
 //
 //	VPSCATTERQD src, k, baseOffset(base)(index*scale)
 func (amd64 *Amd64) VPSCATTERQD(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, src VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPSCATTERQD", src, mask, fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale))
+	amd64.writeOp(comment, "VPSCATTERQD", src, mask, Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset})
 }
 
 // VPSCATTERQQ scatters packed quadwords using signed qword indices.
@@ -1553,7 +1546,7 @@ func (amd64 *Amd64) VPSCATTERQD(baseAddrOffset int, baseAddr Register, indices V
 //
 //	VPSCATTERQQ src, k, baseOffset(base)(index*scale)
 func (amd64 *Amd64) VPSCATTERQQ(baseAddrOffset int, baseAddr Register, indices VectorRegister, scale int, mask MaskRegister, src VectorRegister, comment ...string) {
-	amd64.writeOp(comment, "VPSCATTERQQ", src, mask, fmt.Sprintf("%d(%s)(%s*%d)", baseAddrOffset, baseAddr, indices, scale))
+	amd64.writeOp(comment, "VPSCATTERQQ", src, mask, Mem{Base: baseAddr, Index: indices, Scale: scale, Disp: baseAddrOffset})
 }
 
 // -----------------------------------------------------------------------------
@@ -1577,6 +1570,6 @@ func (amd64 *Amd64) VPSCATTERQQ(baseAddrOffset int, baseAddr Register, indices V
 //	0xAA: result = C
 //	0x96: result = A XOR B XOR C
 //	0xCA: result = (A AND B) OR (NOT(A) AND C)
-func (amd64 *Amd64) VPTERNLOGQ(imm8, r1, r2, r3 interface{}, comment ...string) {
+func (amd64 *Amd64) VPTERNLOGQ(imm8, r1, r2, r3 Operand, comment ...string) {
 	amd64.writeOp(comment, "VPTERNLOGQ", imm8, r1, r2, r3)
 }
@@ -0,0 +1,332 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOperands(t *testing.T) {
+	var buf bytes.Buffer
+	asm := NewAmd64(&buf)
+
+	asm.MOVQ(FPArg{Name: "res", Offset: 8}, AX)
+	asm.MOVQ(Mem{Base: AX, Disp: 16}, BX)
+	asm.MOVQ(Mem{Base: AX, Index: CX, Scale: 8, Disp: -8}, DX)
+	asm.MOVQ(SymRef{Name: "·qElement<>", Offset: 24}, R8)
+	asm.LEAQ(SymRef{Name: "·qElement<>"}, R9)
+	asm.MOVQ(Imm(-1), R10)
+	asm.ADDQ(Imm8(1), R10)
+	asm.ADDQ(UImm32(0x10), R10)
+	asm.SUBQ(Imm32(-8), R10)
+	asm.MOVQ(UImm64(1<<63), R10)
+	asm.VPTERNLOGD(UImm8(0x96), Z1, Z2, Z3)
+	asm.VPGATHERDD(8, AX, Z18, 4, K7, Z6)
+	asm.LEAL(8, AX, BX)
+	x := AX
+	asm.MOVQ(x.At(2), BX)
+	asm.MOVQ(Register("AX"), AX) // skipped
+	asm.MOVQ(Raw("0(AX)"), Mem{Base: AX}, "verbatim operands")
+	if err := asm.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := `    MOVQ res+8(FP), AX
+    MOVQ 16(AX), BX
+    MOVQ -8(AX)(CX*8), DX
+    MOVQ ·qElement<>+24(SB), R8
+    LEAQ ·qElement<>(SB), R9
+    MOVQ $0xffffffffffffffff, R10
+    ADDQ $1, R10
+    ADDQ $0x10, R10
+    SUBQ $-8, R10
+    MOVQ $0x8000000000000000, R10
+    VPTERNLOGD $0x96, Z1, Z2, Z3
+    VPGATHERDD 8(AX)(Z18*4), K7, Z6
+    LEAL 8(AX), BX
+    MOVQ 16(AX), BX
+`
+	if got := buf.String(); !strings.HasPrefix(got, want) || strings.Contains(got, "AX, AX") {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	asm.ADDQ(nil, AX)
+	asm.MOVQ(Mem{Base: AX, Index: CX, Scale: 3}, BX)
+	asm.JMP("")
+	asm.VPGATHERDD(0, AX, Z1, 5, K1, Z2)
+	asm.ADDQ(Imm32(5), Mem{})
+	asm.LEAL(0, "", BX)
+	asm.RET()
+	// invalid instructions are written, for the assembler to fail if the errors aren't checked
+	want = `    ADDQ <missing operand>, AX
+    MOVQ 0(AX)(CX*3), BX
+    JMP 
+    VPGATHERDD 0(AX)(Z1*5), K1, Z2
+    ADDQ $5, 0
+    LEAL 0, BX
+    RET
+`
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	err := asm.Err()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"ADDQ: operand 0: missing operand",
+		"MOVQ: operand 0: memory operand 0(AX)(CX*3): scale must be 1, 2, 4 or 8",
+		"JMP: operand 0: empty label",
+		"VPGATHERDD: operand 0:",
+		"ADDQ: operand 1: memory operand 0 without base nor index",
+		"LEAL: operand 0: memory operand 0 without base nor index",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in errors:\n%v", want, err)
+		}
+	}
+}
+
+func TestForms(t *testing.T) {
+	var buf bytes.Buffer
+	asm := NewAmd64(&buf)
+
+	asm.VPCMPUD(UImm8(1), Z1, Z2, K1)
+	asm.VPCMPUD(UImm8(4), Mem{Base: AX}, Z2, K1)
+	asm.VPADDDk(Z1, Z2, Z4, K3)
+	asm.VPADDD(Z1.Y(), Z2.Y(), Z3.Y())
+	asm.VPMULLQ_BCST(Mem{Base: AX}, Z2, Z3)
+	asm.VPANDDkz(Z1, Z2, K1, Z3)
+	asm.SHRQw(CX, AX, BX)
+	asm.SHRQ(UImm8(63), AX)
+	asm.ADDQ(Imm8(-1), Mem{Base: "SP"})
+	asm.ANDQ(Imm32(-0x80000000), AX)
+	asm.MOVL(UImm32(0xFFFFFFFF), AX)
+	asm.KMOVQ(AX, K2)
+	asm.JNE(Label("done"))
+	asm.XORQ(Raw("acc0"), Raw("acc0")) // macro arguments aren't checked
+	if err := asm.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	asm.VPCMPUD(Imm(256), Z1, Z2, K1)
+	asm.VPADDD(Z1, Z2.Y(), Z3)
+	asm.VPADDDk(Z1, Z2, Z3, K0)
+	asm.VPMULLQ_BCST(Z1, Z2, Z3)
+	asm.SHRQ(DX, AX)
+	asm.ADDQ(AX, Imm(4))
+	asm.ADDQ(Imm(0x80000000), AX)
+	asm.MOVQ(Mem{Base: AX}, Mem{Base: BX})
+	asm.SHRQ(UImm16(1), AX)
+	asm.writeOp(nil, "KMOVQ", K1, K2, K3)
+	// invalid instructions are written, for the assembler to fail if the errors aren't checked
+	if lines := strings.Count(buf.String(), "\n"); lines != 10 || !strings.Contains(buf.String(), "    ADDQ AX, $0x0000000000000004\n") {
+		t.Fatalf("invalid instructions were not written:\n%s", buf.String())
+	}
+	err := asm.Err()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"VPCMPUD: operand 0 ($0x0000000000000100) is out of the imm8 range, expected VPCMPUD imm8 m512/zmm zmm k",
+		"VPADDD: operand 1 (Y2) is a ymm register, expected VPADDD m512/zmm zmm zmm",
+		"VPADDD: operand 2 (K0) is K0, which can't be used as a write mask, expected VPADDD m512/zmm zmm k zmm",
+		"VPMULLQ.BCST: operand 0 (Z1) is a zmm register, expected a memory operand to broadcast",
+		"SHRQ: operand 0 (DX) is not CX, expected SHRQ imm8/cl r64/m64",
+		"ADDQ: operand 1 ($0x0000000000000004) is an immediate, expected ADDQ simm32/r64/m64 r64",
+		"ADDQ: operand 0 ($0x0000000080000000) is out of the simm32 range, expected ADDQ simm32/r64/m64 r64",
+		"MOVQ: operand 1 (0(BX)) is a memory operand, expected MOVQ imm64/r64/m64/xmm r64",
+		"SHRQ: operand 0 ($1) is a 16-bit immediate, expected SHRQ imm8/cl r64/m64",
+		"KMOVQ: no form takes 3 operands, expected one of: KMOVQ k/m64 k; KMOVQ k m64; KMOVQ r64 k; KMOVQ k r64",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in errors:\n%v", want, err)
+		}
+	}
+}
+
+func TestStrict(t *testing.T) {
+	var buf bytes.Buffer
+	asm := NewAmd64(&buf)
+	asm.Strict()
+	asm.MOVQ(AX, BX)
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !strings.Contains(err.Error(), "ADDQ: operand 0 ($0x0000000080000000) is out of the simm32 range") {
+			t.Fatalf("expected a panic with the error, got %v", err)
+		}
+	}()
+	asm.ADDQ(Imm(0x80000000), AX)
+	t.Fatal("expected a panic")
+}
+
+func TestRecord(t *testing.T) {
+	program := func(asm *Amd64) {
+		asm.FnHeader("f", 0, 8)
+		asm.MOVQ(FPArg{Name: "x"}, AX)
+		asm.Comment("loop")
+		asm.LABEL("loop")
+		asm.ADDQ(Imm(1), AX, "increment")
+		asm.ADDQ(Imm(2), AX, "")
+		asm.StartDefine()
+		asm.WriteLn("#define INC(r) \\")
+		asm.INCQ(Raw("r"))
+		asm.EndDefine()
+		asm.JNE("loop")
+		asm.RET()
+	}
+
+	var direct, recorded bytes.Buffer
+	program(NewAmd64(&direct))
+	asm := NewAmd64(&recorded)
+	asm.Record()
+	program(asm)
+	if recorded.Len() != 0 {
+		t.Fatal("recording mode wrote before Flush")
+	}
+	nodes := asm.Nodes()
+	if len(nodes) != 10 {
+		t.Fatalf("expected 10 nodes, got %d", len(nodes))
+	}
+	if n := nodes[4]; n.Kind != NodeInstruction || n.Mnemonic != "ADDQ" || n.Operands[0] != Imm(1) || n.Operands[1] != AX || n.Comment != "increment" {
+		t.Fatalf("unexpected node %#v", n)
+	}
+	if n := nodes[3]; n.Kind != NodeLabel || n.Label != "loop" {
+		t.Fatalf("unexpected node %#v", n)
+	}
+	if err := asm.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if recorded.String() != direct.String() {
+		t.Fatalf("recorded:\n%s\ndirect:\n%s", recorded.String(), direct.String())
+	}
+
+	// nodes can be rewritten before Flush
+	recorded.Reset()
+	asm.XORQ(AX, AX)
+	asm.MOVQ(BX, CX)
+	asm.SetNodes(asm.Nodes()[1:])
+	if err := asm.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if recorded.String() != "    MOVQ BX, CX\n" {
+		t.Fatalf("unexpected output after SetNodes:\n%s", recorded.String())
+	}
+}
+
+func TestPeephole(t *testing.T) {
+	var buf bytes.Buffer
+	asm := NewAmd64(&buf)
+	asm.Record()
+
+	asm.MOVQ(Mem{Base: SI}, AX)
+	asm.MOVQ(AX, BX, "chain")
+	asm.MOVQ(CX, AX) // AX is overwritten: the chain above can be merged
+
+	asm.MOVQ(DX, R8)
+	asm.MOVQ(R8, DX)
+
+	asm.MOVQ(R9, Mem{Base: "SP", Disp: 8})
+	asm.MOVQ(Mem{Base: "SP", Disp: 8}, R10)
+
+	asm.XORQ(R11, R11)
+	asm.XORQ(R11, R11)
+	asm.ADCXQ(R11, R12) // the carry chain needs the flags cleared by XORQ
+
+	asm.XORQ(R13, R13) // R13 and the flags are overwritten: dropped
+	asm.MOVQ(AX, R13)
+	asm.ADDQ(R13, R14)
+
+	asm.LABEL("l")
+	asm.MOVQ(R14, R15)
+	asm.JMP("l")
+	asm.MOVQ(R15, R14) // not after the MOVQ above: a jump is in between
+
+	asm.MOVQ(Z0.X(), AX)
+	asm.MOVQ(AX, Z0.X()) // not redundant: zeroes the upper bits of X0
+
+	rewrites := asm.Peephole()
+	var got []string
+	for _, r := range rewrites {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"move chain: MOVQ 0(SI), AX; MOVQ AX, BX -> MOVQ 0(SI), BX",
+		"redundant move: MOVQ DX, R8; MOVQ R8, DX -> MOVQ DX, R8",
+		"store reload: MOVQ R9, 8(SP); MOVQ 8(SP), R10 -> MOVQ R9, 8(SP); MOVQ R9, R10",
+		"redundant xor: XORQ R11, R11; XORQ R11, R11 -> XORQ R11, R11",
+		"redundant xor: XORQ R13, R13 -> (nothing)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got rewrites:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if err := asm.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	wantAsm := `    MOVQ 0(SI), BX                                         // chain
+    MOVQ CX, AX
+    MOVQ DX, R8
+    MOVQ R9, 8(SP)
+    MOVQ R9, R10
+    XORQ R11, R11
+    ADCXQ R11, R12
+    MOVQ AX, R13
+    ADDQ R13, R14
+l:
+    MOVQ R14, R15
+    JMP l
+    MOVQ R15, R14
+    MOVQ X0, AX
+    MOVQ AX, X0
+`
+	if !strings.HasPrefix(buf.String(), wantAsm) {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), wantAsm)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	var buf bytes.Buffer
+	asm := NewAmd64(&buf)
+
+	asm.FnHeader("f", 0, 8)
+	loop, done := asm.NewLabel("loop"), asm.NewLabel("done")
+	asm.LABEL(loop)
+	asm.DECQ(AX)
+	asm.JNE(loop)
+	asm.JMP(done)
+	asm.LABEL(done)
+	asm.RET()
+	if err := asm.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	asm.FnHeader("g", 0, 8)
+	asm.JEQ(loop)
+	asm.LABEL("twice")
+	asm.LABEL("twice")
+	asm.JMP("missing")
+	asm.LABEL("dead")
+	asm.RET()
+	// g is checked when h starts
+	asm.FnHeader("h", 0, 8)
+	asm.JMP("end")
+	asm.LABEL("end")
+
+	err := asm.Err()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	want := `g: jump to label loop_1, defined in function f
+g: jump to undefined label missing
+g: label twice defined 2 times`
+	if err.Error() != want {
+		t.Fatalf("got errors:\n%v\nwant:\n%s", err, want)
+	}
+	if w := asm.Warnings(); len(w) != 1 || w[0] != "g: block dead is unreachable: it follows a JMP or a RET and no jump targets it" {
+		t.Fatalf("unexpected warnings %q", w)
+	}
+}
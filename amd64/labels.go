@@ -0,0 +1,126 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"fmt"
+	"strings"
+)
+
+// labelScope tracks the labels of the function being written, opened with FnHeader
+type labelScope struct {
+	function    string
+	defined     map[Label]int
+	labels      []Label        // defined labels, in order of definition
+	targets     []Label        // jump targets, in order of first use
+	seen        map[Label]bool // jump targets
+	unreachable []Label        // labels defined right after an unconditional jump or a RET
+	transfer    bool           // the last instruction is an unconditional jump or a RET
+}
+
+func newLabelScope(function string) *labelScope {
+	return &labelScope{function: function, defined: map[Label]int{}, seen: map[Label]bool{}}
+}
+
+// trackLabels records the label definitions and the jumps of the current function
+func (amd64 *Amd64) trackLabels(n Node) {
+	s := amd64.scope
+	if s == nil || amd64.defineMode {
+		return
+	}
+	switch n.Kind {
+	case NodeLabel:
+		s.defined[n.Label]++
+		if s.defined[n.Label] == 1 {
+			s.labels = append(s.labels, n.Label)
+			amd64.labelFunctions[n.Label] = append(amd64.labelFunctions[n.Label], s.function)
+		}
+		if s.transfer {
+			s.unreachable = append(s.unreachable, n.Label)
+		}
+		s.transfer = false
+	case NodeInstruction:
+		s.transfer = n.Mnemonic == "JMP"
+		if l, ok := jumpTarget(n); ok && !s.seen[l] {
+			s.seen[l] = true
+			s.targets = append(s.targets, l)
+		}
+	case NodeRaw:
+		switch strings.TrimSpace(n.Text) {
+		case "":
+		case "RET":
+			s.transfer = true
+		default:
+			s.transfer = false
+		}
+	}
+}
+
+// jumpTarget returns the label a jump instruction targets
+func jumpTarget(n Node) (Label, bool) {
+	if !strings.HasPrefix(n.Mnemonic, "J") || len(n.Operands) != 1 {
+		return "", false
+	}
+	switch c := classify(n.Operands[0]); c.kind {
+	case kindLabel, kindUnknown:
+		return Label(n.Operands[0].String()), true
+	}
+	return "", false
+}
+
+// check returns the errors and warnings of the function: jumps to labels it doesn't define (possibly defined in
+// another function), labels defined more than once, and labelled blocks which can't be reached
+func (s *labelScope) check(labelFunctions map[Label][]string) (errs []error, warnings []string) {
+	for _, l := range s.targets {
+		if s.defined[l] != 0 {
+			continue
+		}
+		var elsewhere []string
+		for _, f := range labelFunctions[l] {
+			if f != s.function {
+				elsewhere = append(elsewhere, f)
+			}
+		}
+		if len(elsewhere) > 0 {
+			errs = append(errs, fmt.Errorf("%s: jump to label %s, defined in function %s", s.function, l, strings.Join(elsewhere, ", ")))
+		} else {
+			errs = append(errs, fmt.Errorf("%s: jump to undefined label %s", s.function, l))
+		}
+	}
+	for _, l := range s.labels {
+		if count := s.defined[l]; count > 1 {
+			errs = append(errs, fmt.Errorf("%s: label %s defined %d times", s.function, l, count))
+		}
+	}
+	for _, l := range s.unreachable {
+		if !s.seen[l] {
+			warnings = append(warnings, fmt.Sprintf("%s: block %s is unreachable: it follows a JMP or a RET and no jump targets it", s.function, l))
+		}
+	}
+	return errs, warnings
+}
+
+// endFunction checks the labels of the current function, if any
+func (amd64 *Amd64) endFunction() {
+	if amd64.scope == nil {
+		return
+	}
+	errs, warnings := amd64.scope.check(amd64.labelFunctions)
+	amd64.scope = nil
+	amd64.warnings = append(amd64.warnings, warnings...)
+	for _, err := range errs {
+		amd64.addErr(err)
+	}
+}
+
+// Warnings returns the warnings of the label checks: labelled blocks that no jump targets and that follow an
+// unconditional jump or a RET. Like Err, it includes the function being written.
+func (amd64 *Amd64) Warnings() []string {
+	warnings := append([]string{}, amd64.warnings...)
+	if amd64.scope != nil {
+		_, w := amd64.scope.check(amd64.labelFunctions)
+		warnings = append(warnings, w...)
+	}
+	return warnings
+}
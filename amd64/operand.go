@@ -0,0 +1,200 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operand is an operand of an instruction. The interface is sealed: operands are registers (Register,
+// VectorRegister, MaskRegister), immediates (Imm and its sized variants), memory references (Mem, FPArg,
+// SymRef), labels, and Raw text for the syntax the other types don't cover.
+//
+// Instruction methods take Operand values, so that an argument of another type doesn't compile. Operands which
+// are still invalid, like a Mem without base nor index, are reported by Amd64.Err.
+type Operand interface {
+	String() string
+
+	// check returns an error if the operand can't be written
+	check() error
+}
+
+// Imm is an immediate value. Negative values are written as their 64-bit two's complement.
+type Imm int64
+
+// Imm8, Imm16 and Imm32 are signed immediates of a given width, written in decimal. They don't match the forms
+// of an instruction taking a narrower immediate.
+type (
+	Imm8  int8
+	Imm16 int16
+	Imm32 int32
+)
+
+// UImm8, UImm16, UImm32 and UImm64 are unsigned immediates of a given width, like the bit patterns of shuffles
+// and ternary logic, written in hexadecimal from 10 on.
+type (
+	UImm8  uint8
+	UImm16 uint16
+	UImm32 uint32
+	UImm64 uint64
+)
+
+// Mem is a memory reference Disp(Base)(Index*Scale). Index may be a Register or, for gather and scatter
+// instructions, a VectorRegister.
+type Mem struct {
+	Base  Register
+	Index Operand
+	Scale int
+	Disp  int
+}
+
+// FPArg is a function argument, Name+Offset(FP)
+type FPArg struct {
+	Name   string
+	Offset int
+}
+
+// SymRef is a reference to a symbol, Name+Offset(SB), for instance ·myConst<>+8(SB)
+type SymRef struct {
+	Name   string
+	Offset int
+}
+
+// Raw is an operand written verbatim, for instance a macro argument. It matches any form of the instructions.
+type Raw string
+
+func (r Register) String() string        { return string(r) }
+func (vr VectorRegister) String() string { return string(vr) }
+func (k MaskRegister) String() string    { return string(k) }
+func (l Label) String() string           { return string(l) }
+
+func (i Imm) String() string {
+	switch i {
+	case 0:
+		return "$0"
+	case 1:
+		return "$1"
+	}
+	return fmt.Sprintf("$%#016x", uint64(i))
+}
+
+func (i Imm8) String() string  { return fmt.Sprintf("$%d", i) }
+func (i Imm16) String() string { return fmt.Sprintf("$%d", i) }
+func (i Imm32) String() string { return fmt.Sprintf("$%d", i) }
+
+func (i UImm8) String() string  { return unsignedImm(uint64(i)) }
+func (i UImm16) String() string { return unsignedImm(uint64(i)) }
+func (i UImm32) String() string { return unsignedImm(uint64(i)) }
+func (i UImm64) String() string { return unsignedImm(uint64(i)) }
+
+func unsignedImm(v uint64) string {
+	if v < 10 {
+		return fmt.Sprintf("$%d", v)
+	}
+	return fmt.Sprintf("$%#x", v)
+}
+
+func (m Mem) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d", m.Disp)
+	if m.Base != "" {
+		sb.WriteString("(" + string(m.Base) + ")")
+	}
+	if m.Index != nil {
+		fmt.Fprintf(&sb, "(%s*%d)", m.Index, m.Scale)
+	}
+	return sb.String()
+}
+
+func (a FPArg) String() string {
+	return fmt.Sprintf("%s%+d(FP)", a.Name, a.Offset)
+}
+
+func (s SymRef) String() string {
+	if s.Offset == 0 {
+		return s.Name + "(SB)"
+	}
+	return fmt.Sprintf("%s%+d(SB)", s.Name, s.Offset)
+}
+
+func (r Raw) String() string { return string(r) }
+
+func (r Register) check() error {
+	if r == "" {
+		return fmt.Errorf("empty register")
+	}
+	return nil
+}
+
+func (vr VectorRegister) check() error {
+	if vr == "" {
+		return fmt.Errorf("empty vector register")
+	}
+	return nil
+}
+
+func (k MaskRegister) check() error {
+	if k == "" {
+		return fmt.Errorf("empty mask register")
+	}
+	return nil
+}
+
+func (l Label) check() error {
+	if l == "" {
+		return fmt.Errorf("empty label")
+	}
+	return nil
+}
+
+func (i Imm) check() error    { return nil }
+func (i Imm8) check() error   { return nil }
+func (i Imm16) check() error  { return nil }
+func (i Imm32) check() error  { return nil }
+func (i UImm8) check() error  { return nil }
+func (i UImm16) check() error { return nil }
+func (i UImm32) check() error { return nil }
+func (i UImm64) check() error { return nil }
+
+func (m Mem) check() error {
+	if m.Base == "" && m.Index == nil {
+		return fmt.Errorf("memory operand %s without base nor index", m)
+	}
+	if m.Index == nil {
+		return nil
+	}
+	switch m.Index.(type) {
+	case Register, VectorRegister:
+	default:
+		return fmt.Errorf("memory operand %s: index must be a register or a vector register, got %T", m, m.Index)
+	}
+	switch m.Scale {
+	case 1, 2, 4, 8:
+	default:
+		return fmt.Errorf("memory operand %s: scale must be 1, 2, 4 or 8", m)
+	}
+	return m.Index.check()
+}
+
+func (a FPArg) check() error {
+	if a.Name == "" {
+		return fmt.Errorf("argument %s without name", a)
+	}
+	return nil
+}
+
+func (s SymRef) check() error {
+	if s.Name == "" {
+		return fmt.Errorf("symbol reference %s without name", s)
+	}
+	return nil
+}
+
+func (r Raw) check() error {
+	if strings.TrimSpace(string(r)) == "" {
+		return fmt.Errorf("empty operand")
+	}
+	return nil
+}
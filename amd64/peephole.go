@@ -0,0 +1,333 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rewrite is a change made by the peephole pass
+type Rewrite struct {
+	Rule   string // "move chain", "redundant move", "store reload" or "redundant xor"
+	Before []Node
+	After  []Node
+}
+
+func (r Rewrite) String() string {
+	join := func(nodes []Node) string {
+		if len(nodes) == 0 {
+			return "(nothing)"
+		}
+		s := make([]string, len(nodes))
+		for i, n := range nodes {
+			s[i] = strings.TrimSpace(formatOp(n.Mnemonic, n.Operands, false, ""))
+		}
+		return strings.Join(s, "; ")
+	}
+	return r.Rule + ": " + join(r.Before) + " -> " + join(r.After)
+}
+
+// Peephole runs a peephole pass over the recorded nodes (see Record) and returns the changes made. It rewrites
+// consecutive instructions, with only comments in between:
+//
+//	move chain      MOVQ a, b; MOVQ b, c      -> MOVQ a, c  if b is overwritten before being read again
+//	redundant move  MOVQ x, y; MOVQ y, x      -> MOVQ x, y
+//	store reload    MOVQ r, m; MOVQ m, r2     -> MOVQ r, m; MOVQ r, r2
+//	redundant xor   XORQ r, r; XORQ r, r      -> XORQ r, r
+//	redundant xor   XORQ r, r                 -> (nothing)  if r and the flags are overwritten before being read
+//
+// The pass is conservative: labels, jumps, lines written with WriteLn, macro bodies and operands it doesn't
+// understand (macro arguments) stop the analysis, and registers and flags are considered live there. In particular
+// a XORQ clearing the flags before an ADCXQ / ADOXQ carry chain is kept, and a MOVQ to or from a vector register,
+// which isn't a plain copy, is never rewritten.
+func (amd64 *Amd64) Peephole() []Rewrite {
+	var rewrites []Rewrite
+	nodes := amd64.nodes
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(nodes); i++ {
+			if r, next, ok := peephole(nodes, i); ok {
+				rewrites = append(rewrites, r)
+				nodes = next
+				changed = true
+			}
+		}
+	}
+	amd64.nodes = nodes
+	return rewrites
+}
+
+// peephole tries the rules on the instruction at index i
+func peephole(nodes []Node, i int) (Rewrite, []Node, bool) {
+	a := nodes[i]
+	if !optimizable(a) {
+		return Rewrite{}, nil, false
+	}
+	j := nextInstruction(nodes, i)
+	var b Node
+	if j >= 0 {
+		b = nodes[j]
+	}
+	// replace replaces a and b with after; the comments in between are kept
+	replace := func(rule string, after ...Node) (Rewrite, []Node, bool) {
+		next := append([]Node{}, nodes[:i]...)
+		if len(after) > 0 {
+			next = append(next, after[0])
+		}
+		next = append(next, nodes[i+1:j]...)
+		if len(after) > 1 {
+			next = append(next, after[1])
+		}
+		next = append(next, nodes[j+1:]...)
+		return Rewrite{Rule: rule, Before: []Node{a, b}, After: after}, next, true
+	}
+
+	switch a.Mnemonic {
+	case "MOVQ":
+		if j < 0 || b.Mnemonic != "MOVQ" || !plainMove(a) || !plainMove(b) {
+			break
+		}
+		src, dst := a.Operands[0], a.Operands[1]
+		bSrc, bDst := b.Operands[0], b.Operands[1]
+		if bSrc.String() != dst.String() {
+			break
+		}
+		cSrc, cDst := classify(src), classify(dst)
+		if cDst.kind == kindRegister && !mentions(bDst, cDst.name) && isDead(nodes, j, cDst.name) {
+			if src.String() == bDst.String() {
+				return replace("move chain")
+			}
+			merged := Instruction("MOVQ", []Operand{src, bDst}, comments(b, a)...)
+			if cSrc.kind == kindImm && !cSrc.immKnown && classify(bDst).kind != kindRegister {
+				break
+			}
+			if checkForm("MOVQ", merged.Operands) == nil {
+				return replace("move chain", merged)
+			}
+		}
+		if bDst.String() == src.String() && !(cSrc.kind == kindMemory && cDst.kind == kindRegister && mentions(src, cDst.name)) {
+			return replace("redundant move", a)
+		}
+		if cSrc.kind == kindRegister && cDst.kind == kindMemory && classify(bDst).kind == kindRegister {
+			return replace("store reload", a, Instruction("MOVQ", []Operand{src, bDst}, comments(b)...))
+		}
+	case "XORQ":
+		r := a.Operands[0]
+		if r.String() != a.Operands[1].String() || classify(r).kind != kindRegister {
+			break
+		}
+		if j >= 0 && b.Mnemonic == "XORQ" && b.Operands[0].String() == r.String() && b.Operands[1].String() == r.String() {
+			return replace("redundant xor", a)
+		}
+		if isDead(nodes, i, r.String()) && flagsDead(nodes, i) {
+			next := append(append([]Node{}, nodes[:i]...), nodes[i+1:]...)
+			return Rewrite{Rule: "redundant xor", Before: []Node{a}}, next, true
+		}
+	}
+	return Rewrite{}, nil, false
+}
+
+// optimizable reports whether n is an instruction whose operands are all understood, outside of a macro
+func optimizable(n Node) bool {
+	if n.Kind != NodeInstruction || n.define {
+		return false
+	}
+	for _, o := range n.Operands {
+		if classify(o).kind == kindUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// nextInstruction returns the index of the instruction following nodes[i], skipping comments, or -1
+func nextInstruction(nodes []Node, i int) int {
+	for j := i + 1; j < len(nodes); j++ {
+		switch {
+		case nodes[j].Kind == NodeComment:
+			continue
+		case optimizable(nodes[j]):
+			return j
+		}
+		return -1
+	}
+	return -1
+}
+
+// plainMove reports whether the MOVQ n is a plain copy between general purpose registers, memory and
+// immediates: a MOVQ to an XMM register also zeroes its upper bits, one from an XMM register drops them
+func plainMove(n Node) bool {
+	for _, o := range n.Operands {
+		if classify(o).kind == kindVector {
+			return false
+		}
+	}
+	return true
+}
+
+func comments(nodes ...Node) []string {
+	for _, n := range nodes {
+		if n.commented {
+			return []string{n.Comment}
+		}
+	}
+	return nil
+}
+
+var registerToken = regexp.MustCompile(`[A-Z][A-Z0-9]*`)
+
+// registersOf returns the general purpose registers an operand is or uses as address
+func registersOf(o Operand) []string {
+	var r []string
+	for _, t := range registerToken.FindAllString(o.String(), -1) {
+		if gpRegisterNames[t] {
+			r = append(r, t)
+		}
+	}
+	return r
+}
+
+func mentions(o Operand, register string) bool {
+	for _, r := range registersOf(o) {
+		if r == register {
+			return true
+		}
+	}
+	return false
+}
+
+// instructions whose last operand is written and not read
+var writeOnlyDestination = map[string]bool{
+	"MOVQ": true, "MOVL": true, "MOVD": true, "LEAQ": true, "LEAL": true, "POPQ": true, "TZCNTQ": true,
+	"SHRXQ": true, "IMUL3Q": true, "IMUL3L": true, "MULXQ": true, "VMOVQ": true, "VMOVD": true,
+	"PEXTRD": true, "PEXTRQ": true, "VPEXTRQ": true, "KMOVB": true, "KMOVW": true, "KMOVD": true, "KMOVQ": true,
+}
+
+// instructions which write none of their operands
+var readOnlyOperands = map[string]bool{
+	"CMPB": true, "CMPL": true, "CMPQ": true, "TESTB": true, "TESTQ": true, "BTQ": true, "PUSHQ": true,
+	"KORTESTW": true, "KORTESTD": true, "KORTESTQ": true, "KTESTB": true, "KTESTW": true, "KTESTD": true, "KTESTQ": true,
+	"PREFETCHT0": true, "PREFETCHT1": true, "PREFETCHT2": true, "PREFETCHNTA": true,
+}
+
+// registerEffects returns the general purpose registers read and written by the instruction n; ok is false if
+// they can't be determined
+func registerEffects(n Node) (reads, writes []string, ok bool) {
+	if !optimizable(n) || n.Mnemonic == "CALL" {
+		return nil, nil, false
+	}
+	mnemonic, _, _ := strings.Cut(n.Mnemonic, ".")
+	last := len(n.Operands) - 1
+	for i, o := range n.Operands {
+		c := classify(o)
+		if c.kind != kindRegister {
+			reads = append(reads, registersOf(o)...)
+			continue
+		}
+		switch {
+		case readOnlyOperands[mnemonic]:
+			reads = append(reads, c.name)
+		case mnemonic == "MULXQ" && i > 0, writeOnlyDestination[mnemonic] && i == last:
+			writes = append(writes, c.name)
+		case mnemonic == "XCHGQ" || mnemonic == "XCHGL" || i == last:
+			reads, writes = append(reads, c.name), append(writes, c.name)
+		default:
+			reads = append(reads, c.name)
+		}
+	}
+	switch mnemonic {
+	case "MULXQ":
+		reads = append(reads, "DX")
+	case "MULQ":
+		reads, writes = append(reads, "AX"), append(writes, "AX", "DX")
+	case "PUSHQ", "POPQ":
+		reads, writes = append(reads, "SP"), append(writes, "SP")
+	}
+	return reads, writes, true
+}
+
+// isDead reports whether the general purpose register r is written before being read after nodes[i]
+func isDead(nodes []Node, i int, r string) bool {
+	for _, n := range nodes[i+1:] {
+		if n.Kind == NodeComment {
+			continue
+		}
+		if isJump(n) {
+			return false
+		}
+		reads, writes, ok := registerEffects(n)
+		if !ok || contains(reads, r) {
+			return false
+		}
+		if contains(writes, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s []string, e string) bool {
+	for _, x := range s {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isJump(n Node) bool {
+	return n.Kind == NodeInstruction && (strings.HasPrefix(n.Mnemonic, "J") || n.Mnemonic == "CALL")
+}
+
+type flagEffect int
+
+const (
+	flagsUnknown flagEffect = iota // stops the analysis: the flags are considered live
+	flagsNone                      // neither reads nor writes the flags, or only some of them
+	flagsRead                      // reads (some of) the flags
+	flagsDefine                    // writes all the flags without reading them
+)
+
+var flagEffects = func() map[string]flagEffect {
+	m := map[string]flagEffect{}
+	set := func(e flagEffect, mnemonics ...string) {
+		for _, s := range mnemonics {
+			m[s] = e
+		}
+	}
+	set(flagsRead, "ADCQ", "SBBQ", "ADCXQ", "ADOXQ", "CMOVQCC", "CMOVQCS", "CMOVQEQ", "CMOVLCC")
+	set(flagsDefine, "ADDQ", "SUBQ", "ANDQ", "ORQ", "XORQ", "CMPB", "CMPL", "CMPQ", "TESTB", "TESTQ", "NEGQ",
+		"MULQ", "IMULQ", "IMUL3Q", "IMUL3L", "KORTESTW", "KORTESTD", "KORTESTQ", "KTESTB", "KTESTW", "KTESTD", "KTESTQ")
+	// INCQ and DECQ preserve CF, shifts by 0 and BTQ leave some flags untouched
+	set(flagsNone, "MOVQ", "MOVL", "MOVD", "LEAQ", "LEAL", "MULXQ", "SHRXQ", "PUSHQ", "POPQ", "NOTQ", "XCHGQ", "XCHGL",
+		"INCQ", "DECQ", "SHLQ", "SHRQ", "SARQ", "ROLQ", "RORQ", "BTQ", "BSFQ", "TZCNTQ", "MOVNTIQ",
+		"PREFETCHT0", "PREFETCHT1", "PREFETCHT2", "PREFETCHNTA", "XORPS", "MOVUPS", "PEXTRD", "PEXTRQ")
+	for mnemonic := range forms {
+		if _, ok := m[mnemonic]; !ok && (mnemonic[0] == 'V' || mnemonic[0] == 'K') {
+			m[mnemonic] = flagsNone
+		}
+	}
+	return m
+}()
+
+// flagsDead reports whether the flags are overwritten before being read after nodes[i]
+func flagsDead(nodes []Node, i int) bool {
+	for _, n := range nodes[i+1:] {
+		if n.Kind == NodeComment {
+			continue
+		}
+		if !optimizable(n) {
+			return false
+		}
+		mnemonic, _, _ := strings.Cut(n.Mnemonic, ".")
+		switch flagEffects[mnemonic] {
+		case flagsUnknown, flagsRead:
+			return false
+		case flagsDefine:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package amd64
+
+import (
+	"strings"
+)
+
+// NodeKind is the kind of a recorded Node
+type NodeKind int
+
+const (
+	NodeInstruction NodeKind = iota // instruction written with typed operands
+	NodeLabel                       // label definition (LABEL)
+	NodeComment                     // comment line (Comment)
+	NodeRaw                         // line written verbatim (WriteLn, RET, FnHeader, ...)
+)
+
+// Node is an element of the program recorded by an Amd64 in recording mode (see Record)
+type Node struct {
+	Kind     NodeKind
+	Mnemonic string    // NodeInstruction
+	Operands []Operand // NodeInstruction
+	Comment  string    // trailing comment of a NodeInstruction, text of a NodeComment
+	Label    Label     // NodeLabel
+	Text     string    // NodeRaw, without the trailing newline
+
+	define    bool // recorded between StartDefine and EndDefine
+	commented bool // NodeInstruction with a (possibly empty) comment
+}
+
+// Instruction returns an instruction node; a comment is written after the operands if one is given
+func Instruction(mnemonic string, operands []Operand, comment ...string) Node {
+	n := Node{Kind: NodeInstruction, Mnemonic: mnemonic, Operands: operands}
+	if len(comment) == 1 {
+		n.Comment, n.commented = comment[0], true
+	}
+	return n
+}
+
+// HasComment reports whether the instruction is written with a trailing comment
+func (n Node) HasComment() bool {
+	return n.Kind == NodeComment || n.commented
+}
+
+// String returns the node as written in Go assembly, without the trailing newline
+func (n Node) String() string {
+	switch n.Kind {
+	case NodeInstruction:
+		return formatOp(n.Mnemonic, n.Operands, n.commented, n.Comment)
+	case NodeLabel:
+		return string(n.Label) + ":"
+	case NodeComment:
+		return "    // " + n.Comment
+	}
+	return n.Text
+}
+
+// Record makes amd64 record the program instead of writing it: nothing reaches the writer until Flush. The
+// recorded nodes may be inspected and rewritten with Nodes and SetNodes in the meantime.
+func (amd64 *Amd64) Record() {
+	amd64.recording = true
+}
+
+// Recording reports whether amd64 is in recording mode
+func (amd64 *Amd64) Recording() bool {
+	return amd64.recording
+}
+
+// Nodes returns the nodes recorded since the last Flush
+func (amd64 *Amd64) Nodes() []Node {
+	return amd64.nodes
+}
+
+// SetNodes replaces the recorded nodes, for instance with the output of an analysis or optimization pass
+func (amd64 *Amd64) SetNodes(nodes []Node) {
+	amd64.nodes = nodes
+}
+
+// Flush writes the recorded nodes to the writer, exactly as they would have been written outside of recording
+// mode, and empties the record. It returns Err.
+func (amd64 *Amd64) Flush() error {
+	for _, n := range amd64.nodes {
+		amd64.writeLine(n.String(), n.define)
+	}
+	amd64.nodes = nil
+	return amd64.Err()
+}
+
+// emit writes the node, or records it in recording mode
+func (amd64 *Amd64) emit(n Node) {
+	amd64.trackLabels(n)
+	if amd64.recording {
+		n.define = amd64.defineMode
+		amd64.nodes = append(amd64.nodes, n)
+		return
+	}
+	amd64.writeLine(n.String(), amd64.defineMode)
+}
+
+// formatOp formats an instruction; the trailing comment is aligned on the 50th column after the operands
+func formatOp(mnemonic string, operands []Operand, commented bool, comment string) string {
+	var sb strings.Builder
+	sb.WriteString("    " + mnemonic)
+	l := -2
+	for i, o := range operands {
+		if i == 0 {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(o.String())
+		l += 2 + len(o.String())
+	}
+	if commented {
+		sb.WriteString(strings.Repeat(" ", max(50-l, 0)))
+		sb.WriteString("// " + comment)
+	}
+	return sb.String()
+}
@@ -101,12 +101,14 @@ type Registers struct {
 	vRegisters []VectorRegister
 }
 
-func (r *Register) At(wordOffset int) string {
-	return fmt.Sprintf("%d(%s)", wordOffset*8, string(*r))
+// At returns the memory operand of the wordOffset-th quadword at the address in r
+func (r *Register) At(wordOffset int) Mem {
+	return Mem{Base: *r, Disp: wordOffset * 8}
 }
 
-func (r *Register) AtD(wordOffset int) Register {
-	return Register(fmt.Sprintf("%d(%s)", wordOffset*4, string(*r)))
+// AtD returns the memory operand of the wordOffset-th doubleword at the address in r
+func (r *Register) AtD(wordOffset int) Mem {
+	return Mem{Base: *r, Disp: wordOffset * 4}
 }
 
 func (r *Registers) Available() int {
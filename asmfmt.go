@@ -0,0 +1,295 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"strings"
+)
+
+// asmLineKind classifies the lines of a Go assembly file
+type asmLineKind int
+
+const (
+	asmBlank       asmLineKind = iota
+	asmComment                 // comment-only line
+	asmPreproc                 // #include, #define, #ifdef ...
+	asmDirective               // TEXT, DATA, GLOBL: not indented
+	asmLabel                   // label definition
+	asmInstruction             // indented instruction (or pseudo-instruction)
+	asmVerbatim                // inside a /* */ block comment, left untouched
+)
+
+type asmLine struct {
+	kind         asmLineKind
+	mnemonic     string
+	operands     string
+	comment      string // trailing comment, including the leading "//"
+	text         string // raw text for blank, comment, preprocessor, label and verbatim lines
+	continuation bool   // line ends with a backslash (inside a #define)
+}
+
+// formatAsm formats Go assembly: labels and TEXT/DATA/GLOBL directives start at column 0, instructions are
+// indented with a tab, and in each block of consecutive instructions mnemonics, operands and trailing comments
+// are aligned in columns. Backslashes ending the lines of a #define are aligned too.
+func formatAsm(src []byte) []byte {
+	lines := parseAsm(string(src))
+
+	var out []string
+	var defineLines []int // indexes in out of the lines ending with a backslash, in the current #define
+	flushDefine := func() {
+		if len(defineLines) == 0 {
+			return
+		}
+		width := 0
+		for _, i := range defineLines {
+			width = max(width, visualWidth(out[i]))
+		}
+		for _, i := range defineLines {
+			out[i] += strings.Repeat(" ", width-visualWidth(out[i])+1) + "\\"
+		}
+		defineLines = defineLines[:0]
+	}
+	emit := func(s string, continuation bool) {
+		out = append(out, strings.TrimRight(s, " \t"))
+		if continuation {
+			defineLines = append(defineLines, len(out)-1)
+		} else {
+			flushDefine()
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		switch l.kind {
+		case asmBlank:
+			// collapse consecutive blank lines, drop leading ones
+			if len(out) > 0 && out[len(out)-1] != "" {
+				emit("", l.continuation)
+			}
+			i++
+		case asmVerbatim, asmPreproc:
+			emit(l.text, l.continuation)
+			i++
+		case asmComment:
+			// comments are indented like the code line they precede
+			indent := ""
+			for j := i + 1; j < len(lines); j++ {
+				if lines[j].kind == asmComment {
+					continue
+				}
+				if lines[j].kind == asmInstruction {
+					indent = "\t"
+				}
+				break
+			}
+			emit(indent+l.text, l.continuation)
+			i++
+		case asmLabel:
+			emit(l.text, l.continuation)
+			i++
+		case asmDirective:
+			emit(joinAsm(l.mnemonic, 0, l.operands, 0, l.comment), l.continuation)
+			i++
+		case asmInstruction:
+			// block of instructions, possibly interleaved with comment lines
+			j := i
+			mnemonicWidth, codeWidth := 0, 0
+			for ; j < len(lines) && (lines[j].kind == asmInstruction || lines[j].kind == asmComment); j++ {
+				if lines[j].kind == asmInstruction {
+					mnemonicWidth = max(mnemonicWidth, len(lines[j].mnemonic))
+				}
+			}
+			// trailing comment lines belong to what follows the block
+			for j > i && lines[j-1].kind == asmComment {
+				j--
+			}
+			for k := i; k < j; k++ {
+				if lines[k].kind == asmInstruction {
+					codeWidth = max(codeWidth, visualWidth(joinAsm(lines[k].mnemonic, mnemonicWidth, lines[k].operands, 0, "")))
+				}
+			}
+			for k := i; k < j; k++ {
+				if lines[k].kind == asmComment {
+					emit("\t"+lines[k].text, lines[k].continuation)
+					continue
+				}
+				emit("\t"+joinAsm(lines[k].mnemonic, mnemonicWidth, lines[k].operands, codeWidth, lines[k].comment), lines[k].continuation)
+			}
+			i = j
+		}
+	}
+	flushDefine()
+
+	// drop trailing blank lines
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// joinAsm writes mnemonic and operands; the mnemonic is padded to mnemonicWidth when there are operands,
+// and the trailing comment starts after codeWidth (relative to the mnemonic).
+func joinAsm(mnemonic string, mnemonicWidth int, operands string, codeWidth int, comment string) string {
+	var sb strings.Builder
+	sb.WriteString(mnemonic)
+	if operands != "" {
+		sb.WriteString(strings.Repeat(" ", max(mnemonicWidth-len(mnemonic), 0)+1))
+		sb.WriteString(operands)
+	}
+	if comment != "" {
+		// widths are measured from the indentation tab stop
+		sb.WriteString(strings.Repeat(" ", max(codeWidth-visualWidth(sb.String()), 0)+1))
+		sb.WriteString(comment)
+	}
+	return sb.String()
+}
+
+func parseAsm(src string) []asmLine {
+	rawLines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	lines := make([]asmLine, 0, len(rawLines))
+	inBlockComment := false
+	for _, raw := range rawLines {
+		raw = strings.TrimRight(raw, " \t")
+		if inBlockComment {
+			lines = append(lines, asmLine{kind: asmVerbatim, text: raw})
+			inBlockComment = !strings.Contains(raw, "*/")
+			continue
+		}
+
+		var l asmLine
+		s := strings.TrimSpace(raw)
+		if strings.HasSuffix(s, "\\") {
+			l.continuation = true
+			s = strings.TrimSpace(strings.TrimSuffix(s, "\\"))
+		}
+
+		switch {
+		case s == "":
+			l.kind = asmBlank
+		case strings.HasPrefix(s, "/*"):
+			l.kind, l.text = asmVerbatim, raw
+			inBlockComment = !strings.Contains(s, "*/")
+			l.continuation = false
+		case strings.HasPrefix(s, "//"):
+			l.kind, l.text = asmComment, s
+		case strings.HasPrefix(s, "#"):
+			l.kind, l.text = asmPreproc, s
+		default:
+			code, comment := splitAsmComment(s)
+			if label, rest, ok := splitAsmLabel(code); ok {
+				lines = append(lines, asmLine{kind: asmLabel, text: label + ":", continuation: l.continuation})
+				if rest == "" && comment == "" {
+					continue
+				}
+				code = rest
+			}
+			l.comment = comment
+			if code == "" {
+				l.kind, l.text = asmComment, comment
+				break
+			}
+			mnemonic, operands := code, ""
+			if i := strings.IndexAny(code, " \t"); i >= 0 {
+				mnemonic, operands = code[:i], code[i+1:]
+			}
+			l.mnemonic = mnemonic
+			l.operands = normalizeAsmOperands(operands)
+			switch mnemonic {
+			case "TEXT", "DATA", "GLOBL":
+				l.kind = asmDirective
+			default:
+				l.kind = asmInstruction
+			}
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// splitAsmComment splits a line of code and its trailing "//" comment, ignoring "//" in string literals
+func splitAsmComment(s string) (code, comment string) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			return strings.TrimSpace(s[:i]), s[i:]
+		}
+	}
+	return s, ""
+}
+
+// splitAsmLabel recognizes a label definition at the start of a line of code
+func splitAsmLabel(code string) (label, rest string, ok bool) {
+	i := strings.IndexByte(code, ':')
+	if i <= 0 || (i+1 < len(code) && code[i+1] == ':') {
+		return "", "", false
+	}
+	for _, r := range code[:i] {
+		if !(r == '_' || r == '.' || r == '·' || r == '<' || r == '>' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return "", "", false
+		}
+	}
+	if code[0] >= '0' && code[0] <= '9' {
+		return "", "", false
+	}
+	return code[:i], strings.TrimSpace(code[i+1:]), true
+}
+
+// normalizeAsmOperands separates top-level operands with a single ", "
+func normalizeAsmOperands(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	var operands []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			operands = append(operands, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	operands = append(operands, strings.TrimSpace(s[start:]))
+	return strings.Join(operands, ", ")
+}
+
+// visualWidth returns the width of s once tabs are expanded to 8 columns
+func visualWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		if r == '\t' {
+			w += 8 - w%8
+		} else {
+			w++
+		}
+	}
+	return w
+}
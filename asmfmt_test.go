@@ -0,0 +1,45 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"testing"
+)
+
+func TestFormatAsm(t *testing.T) {
+	const src = `#include "textflag.h"
+
+#define ADD(a, b) \
+    ADDQ a,b \
+    ADCQ $0,   CX   // carry \
+
+TEXT ·f(SB), NOSPLIT, $0-8
+    MOVQ x+0(FP), AX // load x
+    XORQ BX, BX
+loop: INCQ BX
+	VPADDD Z1,Z2,Z3
+    RET
+`
+	const want = `#include "textflag.h"
+
+#define ADD(a, b)            \
+	ADDQ a, b            \
+	ADCQ $0, CX // carry \
+
+TEXT ·f(SB), NOSPLIT, $0-8
+	MOVQ x+0(FP), AX // load x
+	XORQ BX, BX
+loop:
+	INCQ   BX
+	VPADDD Z1, Z2, Z3
+	RET
+`
+	got := string(formatAsm([]byte(src)))
+	if got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+	if again := string(formatAsm([]byte(got))); again != got {
+		t.Fatalf("formatting is not idempotent:\n%s", again)
+	}
+}
@@ -7,13 +7,15 @@ package bavard
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"text/template"
@@ -23,7 +25,9 @@ import (
 )
 
 const (
-	EnvFilter = "BAVARD_FILTER" // environment variable to filter generation
+	EnvFilter          = "BAVARD_FILTER"     // environment variable to filter generation, see ParseFilter
+	EnvDryRun          = "BAVARD_DRY_RUN"    // environment variable enabling dry run mode, see DryRun
+	EnvSourceDateEpoch = "SOURCE_DATE_EPOCH" // environment variable fixing the current time, see Clock
 )
 
 // Bavard root object to configure the code generation from text/template
@@ -33,20 +37,56 @@ type Bavard struct {
 	imports     bool
 	docFile     bool
 	packageName string
-	license     string
+	license     *licenseHeader
+	years       YearPolicy
+	clock       func() time.Time
 	generated   string
-	buildTag    string
+	buildTag    BuildConstraint
 	funcs       template.FuncMap
+	checkOnly   bool
+	onResult    func(Result)
+	ctx         context.Context
+	templates   *templateCache
+	cache       *genCache
+	dataHash    func() string
+	fingerprint string
+	templateFS  fs.FS
+	sink        Sink
+	sourceMap   *sourceMap
+	filter      *OutputFilter
+	filterSet   bool
+	dryRun      io.Writer
+	skipped     bool
 }
 
 // BatchGenerator enables more efficient and clean multiple file generation
 type BatchGenerator struct {
-	defaultOpts []func(*Bavard) error
+	defaultOpts    []func(*Bavard) error
+	failFast       bool
+	maxParallelism int
+	onProgress     func(Progress)
+	templates      *templateCache
+	manifest       *manifest
+	cache          *genCache
+	templateFS     fs.FS
+	buildTag       BuildConstraint
+	buildTagOp     BuildTagOp
+	buildTagErr    error
+}
+
+// Progress reports the completion of an entry of a batch generation, see OnProgress
+type Progress struct {
+	Entry   Entry
+	Done    int     // number of entries completed so far, including this one
+	Total   int     // number of entries in the batch
+	Outcome Outcome // outcome of the written file, if Err is nil and not in check mode
+	Err     error
 }
 
 // NewBatchGenerator returns a new BatchGenerator
-func NewBatchGenerator(copyrightHolder string, copyrightYear int, generatedBy string) *BatchGenerator {
-	return &BatchGenerator{
+func NewBatchGenerator(copyrightHolder string, copyrightYear int, generatedBy string, options ...func(*BatchGenerator)) *BatchGenerator {
+	b := &BatchGenerator{
+		templates: newTemplateCache(),
 		defaultOpts: []func(*Bavard) error{
 			Apache2(copyrightHolder, copyrightYear),
 			GeneratedBy(generatedBy),
@@ -55,6 +95,45 @@ func NewBatchGenerator(copyrightHolder string, copyrightYear int, generatedBy st
 			Verbose(true),
 		},
 	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+// MaxParallelism returns a BatchGenerator option setting the maximum number of entries generated
+// concurrently. It defaults to runtime.GOMAXPROCS(0).
+func MaxParallelism(n int) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.maxParallelism = n
+	}
+}
+
+// OnProgress returns a BatchGenerator option. fn is called each time an entry is completed, and replaces the
+// messages printed by Verbose (unless Verbose(true) is explicitly given as an extra option). Calls to fn are
+// serialized.
+func OnProgress(fn func(Progress)) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.onProgress = fn
+	}
+}
+
+// TemplateFS returns a BatchGenerator option reading the templates from fsys (for instance, an embed.FS)
+// instead of the OS file system. baseTmplDir and the templates of the entries are then slash-separated paths
+// in fsys.
+func TemplateFS(fsys fs.FS) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.templateFS = fsys
+	}
+}
+
+// FailFast returns a BatchGenerator option. If set to true, the first failing entry cancels the generation
+// of the entries that have not been written yet, and only the errors that occurred until then are returned.
+// Stale files reported in check mode (see CheckOnly) are not failures.
+func FailFast(v bool) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.failFast = v
+	}
 }
 
 // Entry to be used in batch generation of files
@@ -62,28 +141,34 @@ type Entry struct {
 	File      string
 	Templates []string
 	BuildTag  string
+	Package   string // overrides the package name given to the BatchGenerator, if set
 }
 
+// ShouldGenerate reports whether output is selected by the BAVARD_FILTER environment variable (see ParseFilter).
+// Build tag and package patterns do not match, as they are unknown here; an invalid filter is matched as a
+// substring, as in previous versions.
 func ShouldGenerate(output string) bool {
-	envFilter := os.Getenv(EnvFilter)
-	if envFilter == "" {
-		return true
+	f, err := envFilter()
+	if err != nil {
+		return strings.Contains(output, os.Getenv(EnvFilter))
 	}
-
-	return strings.Contains(output, envFilter)
+	return f.Match(output, "", BuildConstraint{})
 }
 
 // GenerateFromString will concatenate templates and create output file from executing the resulting text/template
 // see other package functions to add options (package name, licensing, build tags, ...)
 func GenerateFromString(output string, templates []string, data interface{}, options ...func(*Bavard) error) error {
-	if !ShouldGenerate(output) {
-		return nil // skip generation
-	}
 	var b Bavard
 
 	var buf bytes.Buffer
 
-	if err := b.config(&buf, output, options...); err != nil {
+	if err := b.config(output, options...); err != nil {
+		return err
+	}
+	if ok, err := b.selected(output); !ok {
+		return err // skip generation
+	}
+	if err := b.header(&buf, output); err != nil {
 		return err
 	}
 
@@ -94,13 +179,19 @@ func GenerateFromString(output string, templates []string, data interface{}, opt
 
 	tmpl := template.New("").Funcs(fnHelpers)
 
+	if err := parseStdlib(tmpl); err != nil {
+		return err
+	}
 	if err := tmplfunc.Parse(tmpl, aggregate(templates)); err != nil {
 		return err
 	}
+	instrument(tmpl)
+	b.sourceMap = newSourceMap(&buf, sourcesFromStrings(tmpl.Name(), templates))
+	tmpl.Funcs(b.sourceMap.funcs())
 
 	// execute template
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return err
+		return b.generationError(output, tmpl, err)
 	}
 
 	return b.create(output, &buf)
@@ -109,13 +200,34 @@ func GenerateFromString(output string, templates []string, data interface{}, opt
 // GenerateFromFiles will concatenate templates and create output file from executing the resulting text/template
 // see other package functions to add options (package name, licensing, build tags, ...)
 func GenerateFromFiles(output string, templateF []string, data interface{}, options ...func(*Bavard) error) error {
-	if !ShouldGenerate(output) {
-		return nil // skip generation
+	return generateFromFiles(output, nil, templateF, data, options...)
+}
+
+// GenerateFromFS is like GenerateFromFiles, with templates read from fsys (for instance, an embed.FS).
+// templateF are slash-separated paths in fsys; as with GenerateFromFiles, templates are named after the base
+// name of their file.
+func GenerateFromFS(output string, fsys fs.FS, templateF []string, data interface{}, options ...func(*Bavard) error) error {
+	if fsys == nil {
+		return errors.New("nil template file system")
 	}
+	return generateFromFiles(output, fsys, templateF, data, options...)
+}
+
+// generateFromFiles reads templates from fsys, or from the OS file system if fsys is nil
+func generateFromFiles(output string, fsys fs.FS, templateF []string, data interface{}, options ...func(*Bavard) error) error {
 	var b Bavard
 	var buf bytes.Buffer
 
-	b.config(&buf, output, options...)
+	if err := b.config(output, options...); err != nil {
+		return err
+	}
+	if ok, err := b.selected(output); !ok {
+		return err // skip generation
+	}
+	if err := b.header(&buf, output); err != nil {
+		return err
+	}
+	b.templateFS = fsys
 
 	// parse templates
 	fnHelpers := helpers()
@@ -126,22 +238,44 @@ func GenerateFromFiles(output string, templateF []string, data interface{}, opti
 	if len(templateF) == 0 {
 		return errors.New("missing templates")
 	}
-	tName := path.Base(templateF[0])
 
-	tmpl := template.New(tName).Funcs(fnHelpers)
+	var cacheKey string
+	if b.cache != nil {
+		var upToDate bool
+		if cacheKey, upToDate = b.cache.lookup(&b, output, templateF, buf.Bytes()); upToDate {
+			b.report(output, Unchanged)
+			return nil
+		}
+	}
 
-	if err := tmplfunc.ParseFiles(tmpl, templateF...); err != nil {
+	var tmpl *template.Template
+	var err error
+	if b.templates != nil {
+		tmpl, err = b.templates.get(fsys, templateF, fnHelpers)
+	} else {
+		tmpl, err = parseFiles(fsys, templateF, fnHelpers)
+	}
+	if err != nil {
 		return err
 	}
+	b.sourceMap = newSourceMap(&buf, sourcesFromFiles(fsys, templateF))
+	tmpl.Funcs(b.sourceMap.funcs())
 
 	// execute template
 	if err := tmpl.Execute(&buf, data); err != nil {
+		return b.generationError(output, tmpl, err)
+	}
+	if err := b.create(output, &buf); err != nil {
 		return err
 	}
-	return b.create(output, &buf)
+	if cacheKey != "" && !b.checkOnly {
+		// the cache is an optimization: failing to update it only means the next run regenerates the file
+		_ = b.cache.store(b.output(), output, cacheKey)
+	}
+	return nil
 }
 
-func (b *Bavard) config(buf *bytes.Buffer, output string, options ...func(*Bavard) error) error {
+func (b *Bavard) config(output string, options ...func(*Bavard) error) error {
 	// default settings
 	b.imports = false
 	b.fmt = false
@@ -155,15 +289,31 @@ func (b *Bavard) config(buf *bytes.Buffer, output string, options ...func(*Bavar
 			return err
 		}
 	}
+	return b.configFilter()
+}
 
-	if b.buildTag != "" {
-		if _, err := buf.WriteString("//go:build  " + b.buildTag + "\n"); err != nil {
+// header writes the build constraint, license, "Code generated" comment and package clause of output
+func (b *Bavard) header(buf *bytes.Buffer, output string) error {
+	if !b.buildTag.IsZero() {
+		if err := b.buildTag.CheckFile(output); err != nil {
+			return err
+		}
+		// the constraint must be followed by a blank line
+		if _, err := buf.WriteString("//go:build " + b.buildTag.String() + "\n\n"); err != nil {
 			return err
 		}
 	}
 
-	if b.license != "" {
-		if _, err := buf.WriteString(b.license + "\n"); err != nil {
+	if b.license != nil {
+		now, err := b.now()
+		if err != nil {
+			return err
+		}
+		header, err := b.license.render(output, b.years, now)
+		if err != nil {
+			return err
+		}
+		if _, err := buf.WriteString(header + "\n\n"); err != nil {
 			return err
 		}
 	}
@@ -171,7 +321,8 @@ func (b *Bavard) config(buf *bytes.Buffer, output string, options ...func(*Bavar
 		return err
 	}
 
-	if !b.docFile && b.packageName != "" {
+	// assembly files have no package clause
+	if !b.docFile && !strings.HasSuffix(output, ".s") && b.packageName != "" {
 		if _, err := buf.WriteString("package " + b.packageName + "\n\n"); err != nil {
 			return err
 		}
@@ -180,45 +331,65 @@ func (b *Bavard) config(buf *bytes.Buffer, output string, options ...func(*Bavar
 }
 
 func (b *Bavard) create(output string, buf *bytes.Buffer) error {
-	// create output dir if not exist
-	_ = os.MkdirAll(filepath.Dir(output), os.ModePerm)
-
-	// create output file
-	file, err := os.Create(output)
+	if b.ctx != nil {
+		if err := b.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	content, err := b.format(output, buf.Bytes())
 	if err != nil {
 		return err
 	}
-	if b.verbose {
-		fmt.Printf("generating %-70s\n", filepath.Clean(output))
+
+	if b.checkOnly {
+		return b.check(output, content)
 	}
-	if _, err := io.Copy(file, buf); err != nil {
-		file.Close()
+
+	outcome, err := writeOutput(b.output(), output, content)
+	if err != nil {
 		return err
 	}
+	b.report(output, outcome)
+	return nil
+}
 
-	file.Close()
+// report prints (see Verbose) and forwards (see OnResult) the outcome of the generation of output
+func (b *Bavard) report(output string, outcome Outcome) {
+	if b.verbose {
+		fmt.Printf("%-10s %-70s\n", outcome, filepath.Clean(output))
+	}
+	if b.onResult != nil {
+		b.onResult(Result{Output: output, Outcome: outcome})
+	}
+}
 
-	// format generated code
+// format applies gofmt and goimports to the generated code, as configured by the Format and Import options.
+// Assembly (.s) outputs are formatted with formatAsm instead.
+func (b *Bavard) format(output string, src []byte) ([]byte, error) {
+	if strings.HasSuffix(output, ".s") {
+		if b.fmt {
+			return formatAsm(src), nil
+		}
+		return src, nil
+	}
+	res := src
+	var err error
 	if b.fmt {
-		cmd := exec.Command("gofmt", "-s", "-w", output)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return err
+		if res, err = gofmt(output, res); err != nil {
+			return nil, b.formatError(output, src, err)
 		}
 	}
-
-	// run goimports on generated code
 	if b.imports {
-		cmd := exec.Command("goimports", "-w", output)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return err
+		formatted := b.fmt
+		if res, err = goimports(b.output(), output, res); err != nil {
+			if formatted {
+				// positions refer to the formatted code, which the source map doesn't cover
+				return nil, fmt.Errorf("%s: %w", filepath.Clean(output), err)
+			}
+			return nil, b.formatError(output, src, err)
 		}
 	}
-
-	return nil
+	return res, nil
 }
 
 func aggregate(values []string) string {
@@ -229,33 +400,6 @@ func aggregate(values []string) string {
 	return sb.String()
 }
 
-// Apache2Header returns a Apache2 header string
-func Apache2Header(copyrightHolder string, year int) string {
-	// get current year from a timestamp
-	currentYear := time.Now().Year()
-	if currentYear > year {
-		apache2 := `
-		// Copyright %d-%d %s
-		// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
-		`
-		return fmt.Sprintf(apache2, year, currentYear, copyrightHolder)
-	} else {
-		apache2 := `
-		// Copyright %d %s
-		// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
-		`
-		return fmt.Sprintf(apache2, year, copyrightHolder)
-	}
-}
-
-// Apache2 returns a bavard option to be used in Generate writing an apache2 license header in the generated file
-func Apache2(copyrightHolder string, year int) func(*Bavard) error {
-	return func(b *Bavard) error {
-		b.license = Apache2Header(copyrightHolder, year)
-		return nil
-	}
-}
-
 // GeneratedBy returns a bavard option to be used in Generate writing a standard
 // "Code generated by 'label' DO NOT EDIT"
 func GeneratedBy(label string) func(*Bavard) error {
@@ -265,10 +409,16 @@ func GeneratedBy(label string) func(*Bavard) error {
 	}
 }
 
-// BuildTag returns a bavard option to be used in Generate adding build tags string on top of the generated file
+// BuildTag returns a bavard option to be used in Generate adding a //go:build constraint on top of the generated
+// file. buildTag is a build constraint expression, such as "amd64 && !purego" (see ParseBuildConstraint). It must
+// not contradict the GOOS and GOARCH implied by the name of the output (see FileConstraint).
 func BuildTag(buildTag string) func(*Bavard) error {
 	return func(b *Bavard) error {
-		b.buildTag = buildTag
+		c, err := ParseBuildConstraint(buildTag)
+		if err != nil {
+			return err
+		}
+		b.buildTag = c
 		return nil
 	}
 }
@@ -289,8 +439,10 @@ func Verbose(v bool) func(*Bavard) error {
 	}
 }
 
-// Format returns a bavard option to be used in Generate. If set to true, will run gofmt on generated file.
-// Or simple tab alignment on .s files
+// Format returns a bavard option to be used in Generate. If set to true, will format the generated file
+// as "gofmt -s" does (in-process, no gofmt binary is needed).
+// On .s files, labels and instructions are indented consistently, and mnemonics, operands, trailing comments
+// and #define line continuations are aligned in columns.
 func Format(v bool) func(*Bavard) error {
 	return func(b *Bavard) error {
 		b.fmt = v
@@ -298,7 +450,11 @@ func Format(v bool) func(*Bavard) error {
 	}
 }
 
-// Import returns a bavard option to be used in Generate. If set to true, will run goimports
+// Import returns a bavard option to be used in Generate. If set to true, will add missing and remove unused
+// imports as goimports does. Standard library imports are resolved in-process, taking into account the
+// declarations of the other files of the package written to the output Sink. Resolving other packages falls
+// back to golang.org/x/tools/imports, which needs the go toolchain (it runs the go command) and reads the
+// package from the file system. Ignored on .s files.
 func Import(v bool) func(*Bavard) error {
 	return func(b *Bavard) error {
 		b.imports = v
@@ -306,9 +462,67 @@ func Import(v bool) func(*Bavard) error {
 	}
 }
 
+// CheckOnly returns a bavard option to be used in Generate. If set to true, the generated (and formatted) code
+// is compared to the file on disk instead of being written; a *StaleError listing the files that differ is returned.
+func CheckOnly(v bool) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.checkOnly = v
+		return nil
+	}
+}
+
+// OnResult returns a bavard option to be used in Generate. fn is called with the outcome (created, updated or
+// unchanged) of each written file. In batch generation, files are generated concurrently and fn must be safe
+// for concurrent use.
+func OnResult(fn func(Result)) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.onResult = fn
+		return nil
+	}
+}
+
+// withContext sets the context of a batch generation: no file is written once ctx is done
+func withContext(ctx context.Context) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.ctx = ctx
+		return nil
+	}
+}
+
+// withTemplateCache makes GenerateFromFiles parse templates through cache
+func withTemplateCache(cache *templateCache) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.templates = cache
+		return nil
+	}
+}
+
+// alsoOnResult adds fn to the OnResult callback already configured, if any
+func alsoOnResult(fn func(Result)) func(*Bavard) error {
+	return func(b *Bavard) error {
+		if prev := b.onResult; prev != nil {
+			b.onResult = func(r Result) {
+				prev(r)
+				fn(r)
+			}
+		} else {
+			b.onResult = fn
+		}
+		return nil
+	}
+}
+
 // Funcs returns a bavard option to be used in Generate. See text/template FuncMap for more info
+//
+// The names of the standard library templates (see StdlibTemplates) are reserved: the option fails if funcs
+// defines one of them.
 func Funcs(funcs template.FuncMap) func(*Bavard) error {
 	return func(b *Bavard) error {
+		for name := range funcs {
+			if stdlibFuncs()[name] {
+				return fmt.Errorf("function %q is a standard library template (see StdlibTemplates)", name)
+			}
+		}
 		b.funcs = funcs
 		return nil
 	}
@@ -320,34 +534,159 @@ func (b *BatchGenerator) Generate(data interface{}, packageName string, baseTmpl
 }
 
 // GenerateWithOptions allows adding extra configuration (helper functions etc.) to a batch generation
+//
+// Entries are generated concurrently and all their errors are returned, joined with errors.Join; each of them
+// is an *EntryError identifying the entry. In check mode, the stale files of all entries are merged in a
+// single *StaleError.
+// Template files are parsed once per BatchGenerator and reused by all the entries (and calls) using them.
 func (b *BatchGenerator) GenerateWithOptions(data interface{}, packageName string, baseTmplDir string, extraOptions []func(*Bavard) error, entries ...Entry) error {
-	var firstError error
-	var lock sync.RWMutex
+	return b.GenerateContext(context.Background(), data, packageName, baseTmplDir, extraOptions, entries...)
+}
+
+// GenerateContext is GenerateWithOptions with a context: once ctx is done, no more files are written, and
+// ctx.Err() is returned along with the errors of the entries completed so far.
+func (b *BatchGenerator) GenerateContext(ctx context.Context, data interface{}, packageName string, baseTmplDir string, extraOptions []func(*Bavard) error, entries ...Entry) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	baseOpts := make([]func(*Bavard) error, 0, len(b.defaultOpts)+len(extraOptions)+1)
+	baseOpts = append(baseOpts, b.defaultOpts...)
+	if b.onProgress != nil {
+		baseOpts = append(baseOpts, Verbose(false))
+	}
+	baseOpts = append(baseOpts, extraOptions...)
+
+	parallelism := b.maxParallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	parallelism = min(parallelism, len(entries))
+
+	errs := make([]error, len(entries))
+	dataHash := sync.OnceValue(func() string { return hashData(data) })
+	if b.manifest != nil {
+		for i, entry := range entries {
+			errs[i] = b.manifest.claim(entry.File)
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	var lock sync.Mutex
+	done := 0
+	jobs := make(chan int)
 	var wg sync.WaitGroup
-	for i := 0; i < len(entries); i++ {
+	for w := 0; w < parallelism; w++ {
 		wg.Add(1)
-		go func(entry Entry) {
+		go func() {
 			defer wg.Done()
-			opts := make([]func(*Bavard) error, len(b.defaultOpts)+len(extraOptions))
-			copy(opts, b.defaultOpts)
-			copy(opts[len(b.defaultOpts):], extraOptions)
-			if entry.BuildTag != "" {
-				opts = append(opts, BuildTag(entry.BuildTag))
-			}
-			opts = append(opts, Package(packageName))
-			for j := 0; j < len(entry.Templates); j++ {
-				entry.Templates[j] = filepath.Join(baseTmplDir, entry.Templates[j])
-			}
-			if err := GenerateFromFiles(entry.File, entry.Templates, data, opts...); err != nil {
-				lock.Lock()
-				if firstError == nil {
-					firstError = err
+			for i := range jobs {
+				outcome, err := b.generateEntry(ctx, entries[i], data, dataHash, packageName, baseTmplDir, baseOpts)
+				errs[i] = err
+				var stale *StaleError
+				if err != nil && b.failFast && !errors.As(err, &stale) {
+					cancel()
+				}
+				if b.onProgress != nil {
+					lock.Lock()
+					done++
+					b.onProgress(Progress{Entry: entries[i], Done: done, Total: len(entries), Outcome: outcome, Err: err})
+					lock.Unlock()
 				}
-				lock.Unlock()
 			}
-		}(entries[i])
+		}()
+	}
+feed:
+	for i := range entries {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed // cancelled by the caller or by a failed entry
+		}
 	}
+	close(jobs)
 	wg.Wait()
 
-	return firstError
+	err := b.joinErrors(entries, errs, ctx.Err() != nil)
+	if parent.Err() != nil {
+		return errors.Join(parent.Err(), err)
+	}
+	return err
+}
+
+// generateEntry generates one entry of a batch, and returns the outcome of the written file
+func (b *BatchGenerator) generateEntry(ctx context.Context, entry Entry, data interface{}, dataHash func() string, packageName, baseTmplDir string, baseOpts []func(*Bavard) error) (Outcome, error) {
+	var outcome Outcome
+	var sink Sink
+	var gen *Bavard
+	opts := make([]func(*Bavard) error, len(baseOpts), len(baseOpts)+7)
+	copy(opts, baseOpts)
+	buildTag, err := b.entryBuildTag(entry)
+	if err != nil {
+		return outcome, err
+	}
+	if !buildTag.IsZero() {
+		opts = append(opts, BuildTag(buildTag.String()))
+	}
+	if entry.Package != "" {
+		packageName = entry.Package
+	}
+	opts = append(opts,
+		Package(packageName),
+		withContext(ctx),
+		withTemplateCache(b.templates),
+		alsoOnResult(func(r Result) { outcome = r.Outcome }),
+		func(b *Bavard) error {
+			sink, gen = b.output(), b
+			return nil
+		},
+	)
+	if b.cache != nil {
+		opts = append(opts, withGenCache(b.cache, dataHash))
+	}
+	templates := make([]string, len(entry.Templates))
+	for j := range entry.Templates {
+		if b.templateFS != nil {
+			templates[j] = path.Join(baseTmplDir, entry.Templates[j])
+		} else {
+			templates[j] = filepath.Join(baseTmplDir, entry.Templates[j])
+		}
+	}
+	if err := generateFromFiles(entry.File, b.templateFS, templates, data, opts...); err != nil {
+		return outcome, err
+	}
+	if b.manifest != nil && gen != nil && !gen.skipped {
+		return outcome, b.manifest.record(sink, entry.File, templates, b.templateFS != nil, dataHash())
+	}
+	return outcome, nil
+}
+
+// joinErrors wraps errs[i] in an *EntryError for entries[i], and merges the stale files reported in check mode.
+// If the generation was cancelled, the entries which failed because of it are not reported.
+func (b *BatchGenerator) joinErrors(entries []Entry, errs []error, cancelled bool) error {
+	var joined []error
+	var stale *StaleError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		var errStale *StaleError
+		if errors.As(err, &errStale) {
+			if stale == nil {
+				stale = new(StaleError)
+			}
+			stale.merge(errStale)
+			continue
+		}
+		if cancelled && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			continue // not a failure of this entry
+		}
+		joined = append(joined, &EntryError{Entry: entries[i], Err: err})
+	}
+	if stale != nil {
+		joined = append(joined, stale)
+	}
+	return errors.Join(joined...)
 }
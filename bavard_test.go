@@ -0,0 +1,150 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// xTemplate is the template generated by most tests
+const xTemplate = "var x = {{.}}\n"
+
+// generateX generates output from xTemplate with data, in package test, with opts
+func generateX(t *testing.T, output string, data interface{}, opts ...func(*Bavard) error) {
+	t.Helper()
+	opts = append([]func(*Bavard) error{Package("test"), Verbose(false)}, opts...)
+	if err := GenerateFromString(output, []string{xTemplate}, data, opts...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTemplate writes the template file dir/name
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "ok.tmpl", xTemplate)
+	writeTemplate(t, dir, "bad.tmpl", "var x = {{.Missing}}\n")
+	writeTemplate(t, dir, "bad2.tmpl", "var y = {{index . 3}}\n")
+	entries := []Entry{
+		{File: filepath.Join(dir, "ok.go"), Templates: []string{"ok.tmpl"}},
+		{File: filepath.Join(dir, "bad.go"), Templates: []string{"bad.tmpl"}, BuildTag: "amd64"},
+		{File: filepath.Join(dir, "bad2.go"), Templates: []string{"bad2.tmpl"}},
+	}
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard")
+	err := bgen.GenerateWithOptions(42, "test", dir, []func(*Bavard) error{Verbose(false)}, entries...)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+	errs := joined.Unwrap()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), err)
+	}
+	for i, want := range []Entry{entries[1], entries[2]} {
+		var eErr *EntryError
+		if !errors.As(errs[i], &eErr) || eErr.Entry.File != want.File || eErr.Entry.BuildTag != want.BuildTag {
+			t.Fatalf("error %d: expected an *EntryError for %s, got %v", i, want.File, errs[i])
+		}
+		var gErr *GenerationError
+		if !errors.As(errs[i], &gErr) {
+			t.Fatalf("error %d: expected a *GenerationError, got %v", i, errs[i])
+		}
+	}
+	if _, err := os.Stat(entries[0].File); err != nil {
+		t.Fatal("valid entry was not generated")
+	}
+}
+
+func TestBatchProgressAndCancel(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "x.tmpl", xTemplate)
+	newEntries := func() []Entry {
+		entries := make([]Entry, 5)
+		for i := range entries {
+			entries[i] = Entry{File: filepath.Join(dir, fmt.Sprintf("x%d.go", i)), Templates: []string{"x.tmpl"}}
+		}
+		return entries
+	}
+
+	var progress []Progress
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", MaxParallelism(2), OnProgress(func(p Progress) {
+		progress = append(progress, p)
+	}))
+	if err := bgen.Generate(42, "test", dir, newEntries()...); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 5 {
+		t.Fatalf("expected 5 progress reports, got %d", len(progress))
+	}
+	for i, p := range progress {
+		if p.Done != i+1 || p.Total != 5 || p.Err != nil || p.Outcome != Created {
+			t.Fatalf("unexpected progress report %+v", p)
+		}
+	}
+
+	// cancelled context: nothing is generated
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	entries := newEntries()
+	for i := range entries {
+		entries[i].File = filepath.Join(dir, "cancelled", filepath.Base(entries[i].File))
+	}
+	err := bgen.GenerateContext(ctx, 42, "test", dir, nil, entries...)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cancelled")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("files generated after cancellation")
+	}
+}
+
+func TestGenerateFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/main.go.tmpl":  {Data: []byte("var x = {{double .}}\n")},
+		"templates/funcs.go.tmpl": {Data: []byte(`{{define "double x"}}{{mul .x 2}}{{end}}`)},
+		"templates/bad.go.tmpl":   {Data: []byte("var x = 1\nvar y = {{.Missing}}\n")},
+	}
+	dir := t.TempDir()
+
+	// templates call functions defined in other files, as with GenerateFromFiles
+	output := filepath.Join(dir, "x.go")
+	templates := []string{"templates/main.go.tmpl", "templates/funcs.go.tmpl"}
+	if err := GenerateFromFS(output, fsys, templates, 21, Package("test"), Verbose(false)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(output); !strings.HasSuffix(string(got), "var x = 42\n") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// errors refer to the path in the file system
+	err := GenerateFromFS(output, fsys, []string{"templates/bad.go.tmpl"}, 21, Package("test"), Verbose(false))
+	var gErr *GenerationError
+	if !errors.As(err, &gErr) || gErr.Template != "templates/bad.go.tmpl" || gErr.Line != 2 {
+		t.Fatalf("expected an error at templates/bad.go.tmpl:2, got %v", err)
+	}
+
+	// batch generation
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", TemplateFS(fsys))
+	entry := Entry{File: filepath.Join(dir, "y.go"), Templates: []string{"main.go.tmpl", "funcs.go.tmpl"}}
+	if err := bgen.GenerateWithOptions(2, "test", "templates", []func(*Bavard) error{Verbose(false)}, entry); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(entry.File); !strings.HasSuffix(string(got), "var x = 4\n") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// big integer template helpers. They accept big.Int, *big.Int, integers, and decimal or hex ("0x...")
+// strings, and return a fresh *big.Int (usable by words64, printList...) unless stated otherwise.
+func bigHelpers() map[string]interface{} {
+	return map[string]interface{}{
+		"bigAdd":      bigAdd,
+		"bigExp":      bigExp,
+		"bigMul":      bigMul,
+		"bitLen":      bitLen,
+		"limbs":       limbs,
+		"modInverse":  modInverse,
+		"modSqrt":     modSqrt,
+		"montgomeryR": montgomeryR,
+		"nPrime":      nPrime,
+		"rSquare":     rSquare,
+		"toHexLimbs":  toHexLimbs,
+	}
+}
+
+// toBig returns a copy of a as a *big.Int
+func toBig(a interface{}) (*big.Int, error) {
+	i, err := toBigInt(a)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(&i), nil
+}
+
+// toBigs converts all the values with toBig
+func toBigs(values ...interface{}) ([]*big.Int, error) {
+	r := make([]*big.Int, len(values))
+	for i, v := range values {
+		var err error
+		if r[i], err = toBig(v); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// toModulus returns q, which must be a positive odd integer
+func toModulus(q interface{}) (*big.Int, error) {
+	m, err := toBig(q)
+	if err != nil {
+		return nil, err
+	}
+	if m.Sign() <= 0 || m.Bit(0) == 0 {
+		return nil, fmt.Errorf("modulus %s must be positive and odd", m)
+	}
+	return m, nil
+}
+
+// bigAdd returns a + b + ...
+func bigAdd(a, b interface{}, more ...interface{}) (*big.Int, error) {
+	values, err := toBigs(append([]interface{}{a, b}, more...)...)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int)
+	for _, v := range values {
+		r.Add(r, v)
+	}
+	return r, nil
+}
+
+// bigMul returns a * b * ...
+func bigMul(a, b interface{}, more ...interface{}) (*big.Int, error) {
+	values, err := toBigs(append([]interface{}{a, b}, more...)...)
+	if err != nil {
+		return nil, err
+	}
+	r := big.NewInt(1)
+	for _, v := range values {
+		r.Mul(r, v)
+	}
+	return r, nil
+}
+
+// bigExp returns base^exp, or base^exp mod m if a modulus is given. exp may be negative if m is given and
+// base is invertible modulo m.
+func bigExp(base, exp interface{}, m ...interface{}) (*big.Int, error) {
+	if len(m) > 1 {
+		return nil, errors.New("bigExp takes at most one modulus")
+	}
+	values, err := toBigs(append([]interface{}{base, exp}, m...)...)
+	if err != nil {
+		return nil, err
+	}
+	var mod *big.Int
+	if len(m) == 1 {
+		if mod = values[2]; mod.Sign() <= 0 {
+			return nil, fmt.Errorf("modulus %s must be positive", mod)
+		}
+	} else if values[1].Sign() < 0 {
+		return nil, fmt.Errorf("negative exponent %s without modulus", values[1])
+	}
+	r := new(big.Int).Exp(values[0], values[1], mod)
+	if r == nil {
+		return nil, fmt.Errorf("%s is not invertible modulo %s", values[0], mod)
+	}
+	return r, nil
+}
+
+// modInverse returns a⁻¹ mod m
+func modInverse(a, m interface{}) (*big.Int, error) {
+	values, err := toBigs(a, m)
+	if err != nil {
+		return nil, err
+	}
+	if values[1].Sign() <= 0 {
+		return nil, fmt.Errorf("modulus %s must be positive", values[1])
+	}
+	r := new(big.Int).ModInverse(values[0], values[1])
+	if r == nil {
+		return nil, fmt.Errorf("%s is not invertible modulo %s", values[0], values[1])
+	}
+	return r, nil
+}
+
+// modSqrt returns a square root of a modulo the prime p
+func modSqrt(a, p interface{}) (*big.Int, error) {
+	values, err := toBigs(a, p)
+	if err != nil {
+		return nil, err
+	}
+	if values[1].Cmp(big.NewInt(2)) == 0 {
+		// 0 and 1 are their own square roots
+		return new(big.Int).Mod(values[0], values[1]), nil
+	}
+	// big.Int.ModSqrt only supports odd primes: it panics or doesn't terminate on other moduli
+	prime, err := toPrime(values[1])
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).ModSqrt(new(big.Int).Mod(values[0], prime), prime)
+	if r == nil {
+		return nil, fmt.Errorf("%s is not a square modulo %s", values[0], prime)
+	}
+	return r, nil
+}
+
+// nbWords returns the number of 64-bit words of q
+func nbWords(q *big.Int) int {
+	return (q.BitLen() + 63) / 64
+}
+
+// montgomeryR returns R mod q, with R = 2^(64*nbWords) the Montgomery constant of the modulus q,
+// that is, one in Montgomery form
+func montgomeryR(q interface{}) (*big.Int, error) {
+	m, err := toModulus(q)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*nbWords(m)))
+	return r.Mod(r, m), nil
+}
+
+// rSquare returns R² mod q, with R the Montgomery constant of the modulus q (see montgomeryR)
+func rSquare(q interface{}) (*big.Int, error) {
+	m, err := toModulus(q)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).Lsh(big.NewInt(1), uint(128*nbWords(m)))
+	return r.Mod(r, m), nil
+}
+
+// nPrime returns -q⁻¹ mod 2⁶⁴, the constant used by the word-by-word Montgomery reduction
+func nPrime(q interface{}) (uint64, error) {
+	m, err := toModulus(q)
+	if err != nil {
+		return 0, err
+	}
+	w := new(big.Int).Lsh(big.NewInt(1), 64)
+	r := new(big.Int).ModInverse(m, w)
+	r.Sub(w, r)
+	return r.Uint64(), nil
+}
+
+// bitLen returns the number of bits of the absolute value of a
+func bitLen(a interface{}) (int, error) {
+	i, err := toBig(a)
+	if err != nil {
+		return 0, err
+	}
+	return i.BitLen(), nil
+}
+
+// limbs returns the n 64-bit words of a, least significant first
+func limbs(n, a interface{}) ([]uint64, error) {
+	nbLimbs, err := toInt64(n)
+	if err != nil {
+		return nil, err
+	}
+	i, err := toBig(a)
+	if err != nil {
+		return nil, err
+	}
+	if i.Sign() < 0 {
+		return nil, fmt.Errorf("negative value %s", i)
+	}
+	if nbLimbs < 0 || int64(nbWords(i)) > nbLimbs {
+		return nil, fmt.Errorf("%s does not fit in %d limbs", i, nbLimbs)
+	}
+	r := make([]uint64, nbLimbs)
+	mask := new(big.Int).SetUint64(^uint64(0))
+	for j, v := 0, new(big.Int); j < len(r); j++ {
+		r[j] = v.And(v.Rsh(i, uint(64*j)), mask).Uint64()
+	}
+	return r, nil
+}
+
+// toHexLimbs returns the 64-bit words of a as hex literals, least significant first
+func toHexLimbs(a interface{}) ([]string, error) {
+	i, err := toBig(a)
+	if err != nil {
+		return nil, err
+	}
+	words, err := limbs(max(nbWords(i), 1), i)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]string, len(words))
+	for j, w := range words {
+		r[j] = fmt.Sprintf("0x%016x", w)
+	}
+	return r, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBigHelpers(t *testing.T) {
+	const q = "21888242871839275222246405745257275088696311157297823662689037894645226208583" // bn254 base field
+	qHex := "0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47"
+	var qBig big.Int
+	qBig.SetString(q, 10)
+	run := func(tmpl string, data interface{}) (string, error) {
+		output := filepath.Join(t.TempDir(), "x.go")
+		if err := GenerateFromString(output, []string{tmpl}, data, Verbose(false)); err != nil {
+			return "", err
+		}
+		content, err := os.ReadFile(output)
+		return strings.TrimPrefix(string(content), "// Code generated by bavard DO NOT EDIT\n\n"), err
+	}
+
+	for tmpl, want := range map[string]string{
+		`{{nPrime .}}`:                 "9786893198990664585",
+		`{{montgomeryR .}}`:            "6350874878119819312338956282401532409788428879151445726012394534686998597021",
+		`{{rSquare .}}`:                "3096616502983703923843567936837374451735540968419076528771170197431451843209",
+		`{{bitLen .}}`:                 "254",
+		`{{printList (toHexLimbs .)}}`: "0x3c208c16d87cfd47, 0x97816a916871ca8d, 0xb85045b68181585d, 0x30644e72e131a029",
+		`{{printList (limbs 5 "0x1_0000000000000002")}}`:                          "2, 1, 0, 0, 0",
+		`{{words64 (bigAdd . 1 "0x2")}}`:                                          "4332616871279656266, 10917124144477883021, 13281191951274694749, 3486998266802970665",
+		`{{bigMul . 0 (bigAdd 1 1)}}`:                                             "0",
+		`{{bigExp 2 10}} {{bigExp 2 -1 7}}`:                                       "1024 4",
+		`{{bigExp (bigMul (modInverse 3 .) 3) 1 .}}`:                              "1",
+		`{{$r := modSqrt 4 .}}{{bigExp $r 2 .}}`:                                  "4",
+		`{{modSqrt 3 2}} {{modSqrt 4 2}}`:                                         "1 0",
+		`{{eq (printList (toHexLimbs .)) (printList (toHexLimbs (bigAdd . 0)))}}`: "true",
+	} {
+		for _, data := range []interface{}{q, qHex, qBig, &qBig} {
+			got, err := run(tmpl, data)
+			if err != nil {
+				t.Fatalf("%s with %T: %v", tmpl, data, err)
+			}
+			if got != want {
+				t.Fatalf("%s with %T: got %q, want %q", tmpl, data, got, want)
+			}
+		}
+	}
+	if qBig.String() != q {
+		t.Fatal("helpers modified their argument")
+	}
+
+	for _, tmpl := range []string{
+		`{{modInverse 0 .}}`,
+		`{{modSqrt 5 .}}`,
+		`{{modSqrt 2 9}}`,
+		`{{modSqrt 4 15}}`,
+		`{{modSqrt 4 8}}`,
+		`{{modSqrt 4 0}}`,
+		`{{limbs 3 .}}`,
+		`{{bigAdd . "12x"}}`,
+		`{{nPrime 4}}`,
+		`{{bigExp 2 -1}}`,
+	} {
+		if _, err := run(tmpl, q); err == nil {
+			t.Fatalf("%s: expected an error", tmpl)
+		}
+	}
+}
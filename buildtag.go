@@ -0,0 +1,254 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BuildConstraint is a parsed //go:build expression. The zero value is the empty constraint, satisfied by any
+// build configuration.
+type BuildConstraint struct {
+	expr constraint.Expr
+}
+
+// ParseBuildConstraint parses a build constraint expression, with or without the leading "//go:build",
+// for instance "amd64 && !purego".
+func ParseBuildConstraint(s string) (BuildConstraint, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "//go:build"))
+	if s == "" {
+		return BuildConstraint{}, nil
+	}
+	expr, err := constraint.Parse("//go:build " + s)
+	if err != nil {
+		return BuildConstraint{}, fmt.Errorf("invalid build constraint %q: %w", s, err)
+	}
+	return BuildConstraint{expr: expr}, nil
+}
+
+// IsZero reports whether c is the empty constraint
+func (c BuildConstraint) IsZero() bool {
+	return c.expr == nil
+}
+
+// String returns the expression of c, as written after "//go:build "
+func (c BuildConstraint) String() string {
+	if c.expr == nil {
+		return ""
+	}
+	return c.expr.String()
+}
+
+// And returns the constraint satisfied when both c and other are
+func (c BuildConstraint) And(other BuildConstraint) BuildConstraint {
+	switch {
+	case c.expr == nil:
+		return other
+	case other.expr == nil:
+		return c
+	}
+	return BuildConstraint{expr: &constraint.AndExpr{X: c.expr, Y: other.expr}}
+}
+
+// Or returns the constraint satisfied when c or other is. The empty constraint is always satisfied, so is
+// the result if c or other is empty.
+func (c BuildConstraint) Or(other BuildConstraint) BuildConstraint {
+	if c.expr == nil || other.expr == nil {
+		return BuildConstraint{}
+	}
+	return BuildConstraint{expr: &constraint.OrExpr{X: c.expr, Y: other.expr}}
+}
+
+// FileConstraint returns the constraint implied by the name of a Go or assembly file, following the
+// *_GOOS, *_GOARCH and *_GOOS_GOARCH conventions of go/build: "x_linux_amd64.s" implies "linux && amd64".
+func FileConstraint(name string) BuildConstraint {
+	goos, goarch := fileOSArch(name)
+	var c BuildConstraint
+	for _, tag := range []string{goos, goarch} {
+		if tag != "" {
+			c = c.And(BuildConstraint{expr: &constraint.TagExpr{Tag: tag}})
+		}
+	}
+	return c
+}
+
+// CheckFile returns an error if c can not be satisfied by a build of the file name, that is, if it
+// contradicts the constraint implied by the file name (see FileConstraint) or is never satisfied.
+func (c BuildConstraint) CheckFile(name string) error {
+	if c.expr == nil {
+		return nil
+	}
+	goos, goarch := fileOSArch(name)
+	oses, arches := knownOSList, knownArchList
+	if goos != "" {
+		oses = []string{goos}
+	}
+	if goarch != "" {
+		arches = []string{goarch}
+	}
+
+	// tags other than GOOS and GOARCH (purego, gc, go1.x...) may take any value
+	free := freeTags(c.expr)
+	if len(free) > 12 {
+		return nil // too many combinations, assume it is satisfiable
+	}
+	for _, goos := range oses {
+		for _, goarch := range arches {
+			for set := 0; set < 1<<len(free); set++ {
+				ok := c.expr.Eval(func(tag string) bool {
+					if i := sort.SearchStrings(free, tag); i < len(free) && free[i] == tag {
+						return set>>i&1 == 1
+					}
+					return matchOSArchTag(tag, goos, goarch)
+				})
+				if ok {
+					return nil
+				}
+			}
+		}
+	}
+	if implied := FileConstraint(name); !implied.IsZero() {
+		return fmt.Errorf("build constraint %q contradicts the file name %s (%s)", c, filepath.Base(name), implied)
+	}
+	return fmt.Errorf("build constraint %q is never satisfied", c)
+}
+
+// fileOSArch returns the GOOS and GOARCH in the suffix of a file name, as in go/build
+func fileOSArch(name string) (goos, goarch string) {
+	name, _, _ = strings.Cut(filepath.Base(name), ".")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return "", ""
+	}
+	l := strings.Split(name[i:], "_") // l[0] is empty: the first element is never a GOOS or GOARCH
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+	n := len(l)
+	switch {
+	case n >= 3 && knownOS[l[n-2]] && knownArch[l[n-1]]:
+		return l[n-2], l[n-1]
+	case n >= 2 && knownOS[l[n-1]]:
+		return l[n-1], ""
+	case n >= 2 && knownArch[l[n-1]]:
+		return "", l[n-1]
+	}
+	return "", ""
+}
+
+// matchOSArchTag reports whether tag is satisfied when building for goos/goarch, as in go/build
+func matchOSArchTag(tag, goos, goarch string) bool {
+	switch {
+	case tag == goos || tag == goarch:
+		return true
+	case tag == "unix":
+		return unixOS[goos]
+	case tag == "linux":
+		return goos == "android"
+	case tag == "solaris":
+		return goos == "illumos"
+	case tag == "darwin":
+		return goos == "ios"
+	}
+	return false
+}
+
+// freeTags returns the sorted tags of expr which are not a GOOS, a GOARCH or "unix"
+func freeTags(expr constraint.Expr) []string {
+	var tags []string
+	for _, tag := range constraintTags(expr) {
+		if !knownOS[tag] && !knownArch[tag] && tag != "unix" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// constraintTags returns the sorted tags of expr
+func constraintTags(expr constraint.Expr) []string {
+	seen := make(map[string]bool)
+	var walk func(constraint.Expr)
+	walk = func(e constraint.Expr) {
+		switch e := e.(type) {
+		case *constraint.TagExpr:
+			seen[e.Tag] = true
+		case *constraint.NotExpr:
+			walk(e.X)
+		case *constraint.AndExpr:
+			walk(e.X)
+			walk(e.Y)
+		case *constraint.OrExpr:
+			walk(e.X)
+			walk(e.Y)
+		}
+	}
+	walk(expr)
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// known GOOS and GOARCH values, from go/build/syslist.go
+var (
+	knownOSList = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+		"linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos"}
+	knownArchList = []string{"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips",
+		"mipsle", "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+		"s390", "s390x", "sparc", "sparc64", "wasm"}
+	knownOS   = toSet(knownOSList)
+	knownArch = toSet(knownArchList)
+	unixOS    = toSet([]string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios",
+		"linux", "netbsd", "openbsd", "solaris"})
+)
+
+func toSet(l []string) map[string]bool {
+	s := make(map[string]bool, len(l))
+	for _, v := range l {
+		s[v] = true
+	}
+	return s
+}
+
+// BuildTagOp tells how the default build tag of a batch (see DefaultBuildTag) is combined with the build tag
+// of an entry
+type BuildTagOp int
+
+const (
+	BuildTagAnd BuildTagOp = iota // the file is built if both the default tag and the entry tag are satisfied
+	BuildTagOr                    // the file is built if the default tag or the entry tag is satisfied
+)
+
+// DefaultBuildTag returns a BatchGenerator option adding the build constraint tag to all the entries. For entries
+// with their own BuildTag, both are combined with op.
+func DefaultBuildTag(tag string, op BuildTagOp) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.buildTag, b.buildTagErr = ParseBuildConstraint(tag)
+		b.buildTagOp = op
+	}
+}
+
+// entryBuildTag returns the build constraint of entry, combined with the default one of the batch
+func (b *BatchGenerator) entryBuildTag(entry Entry) (BuildConstraint, error) {
+	if b.buildTagErr != nil {
+		return BuildConstraint{}, b.buildTagErr
+	}
+	c, err := ParseBuildConstraint(entry.BuildTag)
+	if err != nil {
+		return BuildConstraint{}, err
+	}
+	if c.IsZero() || b.buildTag.IsZero() {
+		return b.buildTag.And(c), nil
+	}
+	if b.buildTagOp == BuildTagOr {
+		return b.buildTag.Or(c), nil
+	}
+	return b.buildTag.And(c), nil
+}
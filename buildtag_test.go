@@ -0,0 +1,74 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildTag(t *testing.T) {
+	for _, tt := range []struct {
+		file, tag, want string
+		fails           bool
+	}{
+		{file: "x.go", tag: "amd64 && !purego", want: "amd64 && !purego"},
+		{file: "x.go", tag: "//go:build (amd64||arm64) && !purego", want: "(amd64 || arm64) && !purego"},
+		{file: "x_amd64.s", tag: "!purego", want: "!purego"},
+		{file: "x_linux_amd64.go", tag: "unix && !purego", want: "unix && !purego"},
+		{file: "x_amd64.s", tag: "arm64", fails: true},
+		{file: "x_windows.go", tag: "unix", fails: true},
+		{file: "x.go", tag: "amd64 && arm64", fails: true},
+		{file: "x.go", tag: "amd64 &&", fails: true},
+	} {
+		output := filepath.Join(t.TempDir(), tt.file)
+		err := GenerateFromString(output, []string{"\n"}, nil, Package("test"), Verbose(false), BuildTag(tt.tag))
+		if tt.fails {
+			if err == nil {
+				t.Fatalf("%s: expected an error for build tag %q", tt.file, tt.tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		content, _ := os.ReadFile(output)
+		if want := "//go:build " + tt.want + "\n\n"; !strings.HasPrefix(string(content), want) {
+			t.Fatalf("%s: expected %q, got:\n%s", tt.file, want, content)
+		}
+	}
+
+	if c := FileConstraint("x_linux_arm64_test.go"); c.String() != "linux && arm64" {
+		t.Fatalf("unexpected file constraint %q", c)
+	}
+	for _, name := range []string{"amd64.s", "x_amd64_bits.go", "linux.go"} {
+		if c := FileConstraint(name); !c.IsZero() {
+			t.Fatalf("%s: unexpected file constraint %q", name, c)
+		}
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "x.tmpl", "var x = 1\n")
+	entries := []Entry{
+		{File: filepath.Join(dir, "a.go"), Templates: []string{"x.tmpl"}},
+		{File: filepath.Join(dir, "b.go"), Templates: []string{"x.tmpl"}, BuildTag: "amd64 || arm64"},
+	}
+	for op, want := range map[BuildTagOp][]string{
+		BuildTagAnd: {"!purego", "!purego && (amd64 || arm64)"},
+		BuildTagOr:  {"!purego", "!purego || amd64 || arm64"},
+	} {
+		bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", DefaultBuildTag("!purego", op))
+		if err := bgen.GenerateWithOptions(nil, "test", dir, []func(*Bavard) error{Verbose(false)}, entries...); err != nil {
+			t.Fatal(err)
+		}
+		for i, e := range entries {
+			content, _ := os.ReadFile(e.File)
+			if !strings.HasPrefix(string(content), "//go:build "+want[i]+"\n\n") {
+				t.Fatalf("%s: expected build tag %q, got:\n%s", e.File, want[i], content)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StaleFile describes a generated file whose content on disk differs from what bavard would generate
+type StaleFile struct {
+	Path string // output path, as given to the generator
+	Diff string // unified diff from the file on disk to the freshly generated content
+}
+
+// StaleError is returned in check mode (see CheckOnly) when at least one output is out of date
+type StaleError struct {
+	Files []StaleFile
+}
+
+func (e *StaleError) Error() string {
+	paths := make([]string, len(e.Files))
+	for i, f := range e.Files {
+		paths[i] = filepath.Clean(f.Path)
+	}
+	if len(paths) == 1 {
+		return fmt.Sprintf("generated file %s is out of date", paths[0])
+	}
+	return fmt.Sprintf("%d generated files are out of date: %s", len(paths), strings.Join(paths, ", "))
+}
+
+// merge adds the stale files of other to e and keeps the list sorted by path
+func (e *StaleError) merge(other *StaleError) {
+	e.Files = append(e.Files, other.Files...)
+	sort.Slice(e.Files, func(i, j int) bool { return e.Files[i].Path < e.Files[j].Path })
+}
+
+// check compares the generated content with the existing output (see Output). It returns a *StaleError if they differ.
+func (b *Bavard) check(output string, content []byte) error {
+	if b.verbose {
+		fmt.Printf("checking   %-70s\n", filepath.Clean(output))
+	}
+	existing, err := b.output().ReadFile(output)
+	missing := errors.Is(err, fs.ErrNotExist)
+	if err != nil && !missing {
+		return err
+	}
+	if !missing && bytes.Equal(existing, content) {
+		return nil
+	}
+	onDisk := "a/" + filepath.ToSlash(filepath.Clean(output))
+	if missing {
+		onDisk = "/dev/null"
+	}
+	return &StaleError{Files: []StaleFile{{
+		Path: output,
+		Diff: unifiedDiff(onDisk, "b/"+filepath.ToSlash(filepath.Clean(output)), existing, content),
+	}}}
+}
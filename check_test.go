@@ -0,0 +1,48 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckOnly(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.go")
+	generateX(t, output, 42)
+
+	// same template and data: nothing to report
+	check := []func(*Bavard) error{Package("test"), Verbose(false), CheckOnly(true)}
+	if err := GenerateFromString(output, []string{xTemplate}, 42, check...); err != nil {
+		t.Fatal(err)
+	}
+
+	// different data: drift is reported and the file is left untouched
+	before, _ := os.ReadFile(output)
+	err := GenerateFromString(output, []string{xTemplate}, 43, check...)
+	var stale *StaleError
+	if !errors.As(err, &stale) {
+		t.Fatalf("expected a *StaleError, got %v", err)
+	}
+	if len(stale.Files) != 1 || stale.Files[0].Path != output {
+		t.Fatalf("unexpected stale files %v", stale.Files)
+	}
+	if diff := stale.Files[0].Diff; !strings.Contains(diff, "-var x = 42\n") || !strings.Contains(diff, "+var x = 43\n") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	after, _ := os.ReadFile(output)
+	if string(before) != string(after) {
+		t.Fatal("check mode modified the output file")
+	}
+
+	// missing file
+	err = GenerateFromString(filepath.Join(dir, "missing.go"), []string{xTemplate}, 42, check...)
+	if !errors.As(err, &stale) || !strings.HasPrefix(stale.Files[0].Diff, "--- /dev/null\n") {
+		t.Fatalf("expected missing file to be reported, got %v", err)
+	}
+}
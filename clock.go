@@ -0,0 +1,56 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Clock returns a bavard option setting the clock giving the current time, which is used for the years of
+// the license header. By default, the current time is read from the SOURCE_DATE_EPOCH environment variable if
+// set (see https://reproducible-builds.org/specs/source-date-epoch/), and from the system clock otherwise.
+//
+// Given the same templates, data and current time, generated files are identical byte for byte.
+func Clock(now func() time.Time) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.clock = now
+		return nil
+	}
+}
+
+// now returns the current time, see Clock
+func (b *Bavard) now() (time.Time, error) {
+	if b.clock != nil {
+		return b.clock(), nil
+	}
+	return defaultNow()
+}
+
+// defaultNow returns the time set by SOURCE_DATE_EPOCH, or the system time
+func defaultNow() (time.Time, error) {
+	t, ok, err := sourceDateEpoch()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ok {
+		return t, nil
+	}
+	return time.Now(), nil
+}
+
+// sourceDateEpoch returns the time set by SOURCE_DATE_EPOCH, if any
+func sourceDateEpoch() (time.Time, bool, error) {
+	s := os.Getenv(EnvSourceDateEpoch)
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s %q: %w", EnvSourceDateEpoch, s, err)
+	}
+	return time.Unix(sec, 0).UTC(), true, nil
+}
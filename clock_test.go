@@ -0,0 +1,58 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReproducible(t *testing.T) {
+	dir := t.TempDir()
+	one, two := big.NewInt(1), big.NewInt(2)
+	data := map[string]interface{}{
+		"m": map[int]*big.Int{10: two, 9: one, 100: two},
+		"s": map[string]int{"b": 2, "a": 1, "c": 3},
+	}
+	tmpl := "var m = `{{pretty .m}}`\nvar s = `{{pretty .s}}`\n"
+	generate := func(name string, opts ...func(*Bavard) error) string {
+		t.Helper()
+		output := filepath.Join(dir, name)
+		opts = append([]func(*Bavard) error{Package("test"), Verbose(false), Apache2("Consensys Software Inc.", 2020)}, opts...)
+		if err := GenerateFromString(output, []string{tmpl}, data, opts...); err != nil {
+			t.Fatal(err)
+		}
+		content, _ := os.ReadFile(output)
+		return string(content)
+	}
+
+	clock := Clock(func() time.Time { return time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC) })
+	got := generate("x.go", clock)
+	for i := 0; i < 10; i++ {
+		if again := generate("x.go", clock); again != got {
+			t.Fatalf("output is not reproducible:\n%s\nthen:\n%s", got, again)
+		}
+	}
+	for _, want := range []string{"Copyright 2020-2031 ", "var m = `map[9:1 10:2 100:2]`", "var s = `map[a:1 b:2 c:3]`"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+
+	t.Setenv(EnvSourceDateEpoch, "1893456000") // 2030-01-01
+	if got := generate("y.go"); !strings.Contains(got, "Copyright 2020-2030 ") {
+		t.Fatalf("%s not honoured:\n%s", EnvSourceDateEpoch, got)
+	}
+	if !strings.Contains(Apache2Header("Consensys Software Inc.", 2020), "Copyright 2020-2030 ") {
+		t.Fatalf("%s not honoured by Apache2Header", EnvSourceDateEpoch)
+	}
+	t.Setenv(EnvSourceDateEpoch, "yesterday")
+	if err := GenerateFromString(filepath.Join(dir, "z.go"), []string{tmpl}, data, Package("test"), Verbose(false), Apache2("Consensys Software Inc.", 2020)); err == nil {
+		t.Fatalf("expected an error for an invalid %s", EnvSourceDateEpoch)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// config describes a batch generation. It is read from a YAML, JSON or TOML file.
+type config struct {
+	Package     string      `json:"package" yaml:"package" toml:"package"`             // default package name of the outputs
+	Templates   string      `json:"templates" yaml:"templates" toml:"templates"`       // directory of the templates, defaults to the config's
+	GeneratedBy string      `json:"generatedBy" yaml:"generatedBy" toml:"generatedBy"` // name in the "Code generated by" line
+	Copyright   copyright   `json:"copyright" yaml:"copyright" toml:"copyright"`
+	License     string      `json:"license" yaml:"license" toml:"license"`          // apache2 (default), mit, bsd3, spdx:<identifier> or file:<path>
+	BuildTag    string      `json:"buildTag" yaml:"buildTag" toml:"buildTag"`       // build constraint added to all entries
+	BuildTagOp  string      `json:"buildTagOp" yaml:"buildTagOp" toml:"buildTagOp"` // "and" (default) or "or", see bavard.DefaultBuildTag
+	Format      bool        `json:"format" yaml:"format" toml:"format"`
+	Imports     bool        `json:"imports" yaml:"imports" toml:"imports"`
+	Data        interface{} `json:"data" yaml:"data" toml:"data"`             // data given to the templates
+	DataFile    string      `json:"dataFile" yaml:"dataFile" toml:"dataFile"` // file to read the data from, instead of Data
+	Entries     []entry     `json:"entries" yaml:"entries" toml:"entries"`
+}
+
+type copyright struct {
+	Holder string `json:"holder" yaml:"holder" toml:"holder"`
+	Year   int    `json:"year" yaml:"year" toml:"year"`
+}
+
+type entry struct {
+	Output    string   `json:"output" yaml:"output" toml:"output"`
+	Templates []string `json:"templates" yaml:"templates" toml:"templates"`
+	BuildTag  string   `json:"buildTag" yaml:"buildTag" toml:"buildTag"`
+	Package   string   `json:"package" yaml:"package" toml:"package"`
+}
+
+// readConfig reads a config file. Relative paths in the file are relative to its directory.
+func readConfig(path string) (*config, error) {
+	var c config
+	if err := decodeFile(path, &c); err != nil {
+		return nil, err
+	}
+	if len(c.Entries) == 0 {
+		return nil, fmt.Errorf("%s: no entries", path)
+	}
+	for i, e := range c.Entries {
+		if e.Output == "" || len(e.Templates) == 0 {
+			return nil, fmt.Errorf("%s: entry %d: output and templates are required", path, i)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	c.Templates = resolve(dir, c.Templates)
+	if c.Templates == "" {
+		c.Templates = dir
+	}
+	c.DataFile = resolve(dir, c.DataFile)
+	if file, ok := strings.CutPrefix(c.License, "file:"); ok {
+		c.License = "file:" + resolve(dir, file)
+	}
+	for i := range c.Entries {
+		c.Entries[i].Output = resolve(dir, c.Entries[i].Output)
+	}
+	c.Data = normalize(c.Data)
+	return &c, nil
+}
+
+// readData reads the data given to the templates from a YAML, JSON or TOML file
+func readData(path string) (interface{}, error) {
+	var data interface{}
+	if err := decodeFile(path, &data); err != nil {
+		return nil, err
+	}
+	return normalize(data), nil
+}
+
+// decodeFile decodes a file according to its extension
+func decodeFile(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if data, ok := v.(*interface{}); ok {
+			var doc yaml.Node
+			if err = yaml.Unmarshal(content, &doc); err == nil {
+				*data, err = yamlValue(&doc)
+			}
+		} else {
+			err = yaml.Unmarshal(content, v)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.UseNumber()
+		err = dec.Decode(v)
+	case ".toml":
+		if _, err = toml.Decode(string(content), v); err != nil && strings.Contains(err.Error(), "out of range for int64") {
+			// unlike JSON and YAML, TOML has no arbitrary precision integers
+			err = fmt.Errorf("%w: TOML integers are 64-bit, quote larger integers", err)
+		}
+	default:
+		return fmt.Errorf("%s: unknown format %q, expected .yaml, .yml, .json or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// UnmarshalYAML decodes the config as yaml.Unmarshal does, except for Data which is decoded with yamlValue
+func (c *config) UnmarshalYAML(n *yaml.Node) error {
+	type plain config
+	if err := n.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "data" {
+			data, err := yamlValue(n.Content[i+1])
+			if err != nil {
+				return err
+			}
+			c.Data = data
+		}
+	}
+	return nil
+}
+
+// yamlValue decodes n into an untyped value as yaml.Unmarshal does, except that integers which don't fit in
+// 64 bits become *big.Int instead of (rounded) float64, as JSON numbers do
+func yamlValue(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(n.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.SequenceNode:
+		l := make([]interface{}, len(n.Content))
+		for i, e := range n.Content {
+			var err error
+			if l[i], err = yamlValue(e); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, e := n.Content[i], n.Content[i+1]
+			v, err := yamlValue(e)
+			if err != nil {
+				return nil, err
+			}
+			if k.ShortTag() == "!!merge" {
+				merge(m, v)
+				continue
+			}
+			m[k.Value] = v
+		}
+		return m, nil
+	}
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	// yaml.v3 resolves untagged integers out of the 64-bit range as floats
+	if _, isFloat := v.(float64); isFloat && n.Style&yaml.TaggedStyle == 0 {
+		if i, ok := new(big.Int).SetString(n.Value, 0); ok {
+			return i, nil
+		}
+	}
+	return v, nil
+}
+
+// merge adds the entries of a YAML merge key value (a mapping or a sequence of mappings) missing from m
+func merge(m map[string]interface{}, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if _, ok := m[k]; !ok {
+				m[k] = e
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			merge(m, e)
+		}
+	}
+}
+
+// normalize converts the values decoded from the different formats to the types expected by the template
+// helpers: JSON numbers become int64, *big.Int or float64, and maps have string keys.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if i, ok := new(big.Int).SetString(v.String(), 10); ok {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	case []map[string]interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = normalize(e)
+		}
+		return l
+	}
+	return v
+}
+
+func resolve(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,18 @@
+module github.com/consensys/bavard/cmd/bavard
+
+go 1.22.0
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	// the library of this repository is used through go.work during development: bump this requirement to the
+	// release providing the API the command uses before tagging cmd/bavard
+	github.com/consensys/bavard v0.2.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/tools v0.30.0 // indirect
+	rsc.io/tmplfunc v0.0.3 // indirect
+)
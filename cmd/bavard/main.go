@@ -0,0 +1,162 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+// Command bavard generates files from templates, with the data and the list of outputs described in a YAML,
+// JSON or TOML config file, so that generation can be driven without writing Go code.
+//
+// Usage:
+//
+//	bavard [-config bavard.yaml] [-data file] [-filter patterns] [-n] [-check] [-q] [-j n]
+//
+// A config file lists the entries to generate, and the data given to their templates:
+//
+//	package: fp
+//	templates: ./templates
+//	generatedBy: bavard
+//	copyright: {holder: Consensys Software Inc., year: 2020}
+//	buildTag: "!purego"
+//	format: true
+//	data: {nbLimbs: 4}
+//	entries:
+//	  - output: element.go
+//	    templates: [element.go.tmpl, ops.go.tmpl]
+//	  - output: element_amd64.s
+//	    templates: [element.s.tmpl]
+//	    buildTag: amd64
+//
+// Relative paths are relative to the directory of the config file, templates of the entries are relative to
+// the templates directory. The data can be read from a separate file (dataFile in the config, or the -data
+// flag). The package name defaults to $GOPACKAGE, so bavard can be run from a go:generate directive:
+//
+//	//go:generate go run github.com/consensys/bavard/cmd/bavard -config gen.yaml
+//
+// The command is a module of its own, which keeps its YAML and TOML dependencies out of the library's: the
+// module running the directive requires it (go get github.com/consensys/bavard/cmd/bavard).
+//
+// As with the library, only the outputs selected by $BAVARD_FILTER (or -filter) are generated, and only listed
+// if $BAVARD_DRY_RUN is set (or with -n). See bavard.ParseFilter for the syntax of the filter.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/consensys/bavard"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "bavard:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("bavard", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "bavard.yaml", "config file (.yaml, .yml, .json or .toml)")
+	dataPath := flags.String("data", "", "data file (.yaml, .yml, .json or .toml), overrides the data of the config")
+	filter := flags.String("filter", "", "comma-separated patterns selecting the outputs to generate, instead of $BAVARD_FILTER")
+	dryRun := flags.Bool("n", false, "list the outputs which would be generated, without generating them")
+	check := flags.Bool("check", false, "check that the outputs are up to date instead of writing them")
+	quiet := flags.Bool("q", false, "do not list the generated files")
+	parallelism := flags.Int("j", 0, "maximum number of files generated concurrently (default GOMAXPROCS)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+
+	c, err := readConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	data := c.Data
+	if *dataPath != "" {
+		c.DataFile = *dataPath
+	}
+	if c.DataFile != "" {
+		if data, err = readData(c.DataFile); err != nil {
+			return err
+		}
+	}
+	packageName := c.Package
+	if packageName == "" {
+		packageName = os.Getenv("GOPACKAGE")
+	}
+
+	batchOpts := []func(*bavard.BatchGenerator){bavard.MaxParallelism(*parallelism)}
+	if c.BuildTag != "" {
+		op := bavard.BuildTagAnd
+		switch strings.ToLower(c.BuildTagOp) {
+		case "", "and":
+		case "or":
+			op = bavard.BuildTagOr
+		default:
+			return fmt.Errorf("invalid buildTagOp %q, expected \"and\" or \"or\"", c.BuildTagOp)
+		}
+		batchOpts = append(batchOpts, bavard.DefaultBuildTag(c.BuildTag, op))
+	}
+	generatedBy := c.GeneratedBy
+	if generatedBy == "" {
+		generatedBy = "bavard"
+	}
+	bgen := bavard.NewBatchGenerator(c.Copyright.Holder, c.Copyright.Year, generatedBy, batchOpts...)
+
+	opts, err := c.options()
+	if err != nil {
+		return err
+	}
+	opts = append(opts, bavard.Verbose(!*quiet), bavard.CheckOnly(*check))
+	if *filter != "" {
+		opts = append(opts, bavard.Filter(*filter))
+	}
+	if *dryRun {
+		opts = append(opts, bavard.DryRun(os.Stdout))
+	}
+
+	entries := make([]bavard.Entry, len(c.Entries))
+	for i, e := range c.Entries {
+		entries[i] = bavard.Entry{File: e.Output, Templates: e.Templates, BuildTag: e.BuildTag, Package: e.Package}
+	}
+	err = bgen.GenerateWithOptions(data, packageName, c.Templates, opts, entries...)
+	var stale *bavard.StaleError
+	if errors.As(err, &stale) {
+		for _, f := range stale.Files {
+			fmt.Fprint(stderr, f.Diff)
+		}
+	}
+	return err
+}
+
+// options returns the bavard options set by the config
+func (c *config) options() ([]func(*bavard.Bavard) error, error) {
+	opts := []func(*bavard.Bavard) error{bavard.Format(c.Format), bavard.Import(c.Imports)}
+
+	holder := c.Copyright.Holder
+	switch license := c.License; {
+	case license == "" || strings.EqualFold(license, "apache2"):
+		// default license of the BatchGenerator
+	case strings.EqualFold(license, "mit"):
+		opts = append(opts, bavard.MIT(holder))
+	case strings.EqualFold(license, "bsd3"):
+		opts = append(opts, bavard.BSD3(holder))
+	case strings.HasPrefix(license, "spdx:"):
+		opts = append(opts, bavard.SPDX(strings.TrimPrefix(license, "spdx:"), holder))
+	case strings.HasPrefix(license, "file:"):
+		opts = append(opts, bavard.LicenseFile(strings.TrimPrefix(license, "file:")))
+	default:
+		return nil, fmt.Errorf("invalid license %q, expected apache2, mit, bsd3, spdx:<identifier> or file:<path>", license)
+	}
+	if c.Copyright.Year != 0 {
+		opts = append(opts, bavard.LicenseYears(bavard.YearRange(c.Copyright.Year)))
+	} else {
+		opts = append(opts, bavard.LicenseYears(bavard.CurrentYear()))
+	}
+	return opts, nil
+}
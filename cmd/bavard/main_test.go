@@ -0,0 +1,171 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package main
+
+import (
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	configs := map[string]string{
+		"gen.yaml": `package: test
+templates: tmpl
+copyright: {holder: Someone, year: 2020}
+license: mit
+buildTag: "!purego"
+format: true
+data: {name: x, limbs: [1, 2], modulus: "21888242871839275222246405745257275088548364400416034343698204186575808495617"}
+entries:
+  - output: out/x.go
+    templates: [x.tmpl]
+  - output: out/x_amd64.go
+    templates: [x.tmpl]
+    buildTag: amd64
+    package: other
+`,
+		"gen.json": `{
+	"package": "test", "templates": "tmpl", "copyright": {"holder": "Someone", "year": 2020}, "license": "mit",
+	"buildTag": "!purego", "format": true,
+	"data": {"name": "x", "limbs": [1, 2], "modulus": 21888242871839275222246405745257275088548364400416034343698204186575808495617},
+	"entries": [
+		{"output": "out/x.go", "templates": ["x.tmpl"]},
+		{"output": "out/x_amd64.go", "templates": ["x.tmpl"], "buildTag": "amd64", "package": "other"}
+	]
+}`,
+		"gen.toml": `package = "test"
+templates = "tmpl"
+license = "mit"
+buildTag = "!purego"
+format = true
+copyright = {holder = "Someone", year = 2020}
+data = {name = "x", limbs = [1, 2], modulus = "21888242871839275222246405745257275088548364400416034343698204186575808495617"}
+
+[[entries]]
+output = "out/x.go"
+templates = ["x.tmpl"]
+
+[[entries]]
+output = "out/x_amd64.go"
+templates = ["x.tmpl"]
+buildTag = "amd64"
+package = "other"
+`,
+	}
+	const tmpl = "var {{.name}} = [...]int{ {{- printList .limbs}} }\n\nconst modulus = \"{{.modulus}}\"\n"
+	const wantBody = "var x = [...]int{1, 2}\n\nconst modulus = \"21888242871839275222246405745257275088548364400416034343698204186575808495617\"\n"
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "tmpl"), 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "tmpl", "x.tmpl"), []byte(tmpl), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			configPath := filepath.Join(dir, name)
+			if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := run([]string{"-q", "-config", configPath}, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			for file, want := range map[string]string{
+				"x.go":       "//go:build !purego\n\n// Copyright (c) 2020-",
+				"x_amd64.go": "//go:build !purego && amd64\n\n// Copyright (c) 2020-",
+			} {
+				content, err := os.ReadFile(filepath.Join(dir, "out", file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(string(content), want) || !strings.HasSuffix(string(content), wantBody) {
+					t.Fatalf("unexpected %s:\n%s", file, content)
+				}
+			}
+			content, _ := os.ReadFile(filepath.Join(dir, "out", "x_amd64.go"))
+			if !strings.Contains(string(content), "\npackage other\n") {
+				t.Fatalf("package of the entry not used:\n%s", content)
+			}
+
+			if err := run([]string{"-q", "-check", "-config", configPath}, io.Discard); err != nil {
+				t.Fatalf("outputs are up to date, got %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "data.yaml"), []byte("{name: y, limbs: [3], modulus: 7}\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := run([]string{"-q", "-check", "-config", configPath, "-data", filepath.Join(dir, "data.yaml")}, io.Discard); err == nil {
+				t.Fatal("expected stale outputs with other data")
+			}
+		})
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "x.tmpl"), []byte("var x = 1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		config := "package: test\nentries:\n  - {output: a.go, templates: [x.tmpl]}\n  - {output: b.go, templates: [x.tmpl]}\n"
+		if err := os.WriteFile(filepath.Join(dir, "bavard.yml"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("BAVARD_FILTER", "b.go")
+		if err := run([]string{"-q", "-config", filepath.Join(dir, "bavard.yml")}, io.Discard); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "a.go")); err == nil {
+			t.Fatal("filtered out entry was generated")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "b.go")); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestBigIntegers(t *testing.T) {
+	const q = "21888242871839275222246405745257275088548364400416034343698204186575808495617"
+	want, _ := new(big.Int).SetString(q, 10)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"data.yaml": "modulus: " + q + "\nlimbs: [1]\n",
+		"data.json": `{"modulus": ` + q + `, "limbs": [1]}`,
+		"data.toml": "modulus = " + q + "\nlimbs = [1]\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		data, err := readData(path)
+		if filepath.Ext(name) == ".toml" {
+			// TOML integers are 64-bit: the value is rejected
+			if err == nil || !strings.Contains(err.Error(), "quote larger integers") {
+				t.Fatalf("%s: expected an error, got %v", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i, ok := data.(map[string]interface{})["modulus"].(*big.Int); !ok || i.Cmp(want) != 0 {
+			t.Fatalf("%s: modulus decoded as %#v", name, data)
+		}
+	}
+
+	// data in a YAML config
+	path := filepath.Join(dir, "gen.yaml")
+	if err := os.WriteFile(path, []byte("data: {modulus: "+q+"}\nentries: [{output: x.go, templates: [x.tmpl]}]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, ok := c.Data.(map[string]interface{})["modulus"].(*big.Int); !ok || i.Cmp(want) != 0 || len(c.Entries) != 1 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines printed around each hunk
+const diffContext = 3
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+type edit struct {
+	kind editKind
+	a, b int // line index in a (for equal / delete) and b (for equal / insert)
+}
+
+// unifiedDiff returns a unified diff turning a into b, labelled with the given file names.
+// it returns an empty string if a and b are equal.
+func unifiedDiff(nameA, nameB string, a, b []byte) string {
+	linesA, linesB := splitLines(string(a)), splitLines(string(b))
+	edits := myers(linesA, linesB)
+
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	headerLen := sb.Len()
+
+	prevEnd := 0
+	for i := 0; i < len(edits); {
+		// find next change
+		for i < len(edits) && edits[i].kind == editEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// hunk starts diffContext lines before the change and extends while changes are close enough
+		start := max(i-diffContext, prevEnd)
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != editEqual {
+				end++
+				continue
+			}
+			// count equal lines until next change
+			j := end
+			for j < len(edits) && edits[j].kind == editEqual {
+				j++
+			}
+			if j == len(edits) || j-end > 2*diffContext {
+				end = min(end+diffContext, len(edits))
+				break
+			}
+			end = j
+		}
+
+		writeHunk(&sb, edits[start:end], linesA, linesB)
+		i, prevEnd = end, end
+	}
+
+	if sb.Len() == headerLen {
+		return ""
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, edits []edit, linesA, linesB []string) {
+	var startA, startB, countA, countB int
+	startA, startB = -1, -1
+	for _, e := range edits {
+		switch e.kind {
+		case editEqual:
+			countA++
+			countB++
+		case editDelete:
+			countA++
+		case editInsert:
+			countB++
+		}
+		if startA == -1 && e.kind != editInsert {
+			startA = e.a
+		}
+		if startB == -1 && e.kind != editDelete {
+			startB = e.b
+		}
+	}
+	// by convention, an empty range starts at the line preceding it
+	if startA == -1 {
+		startA = edits[0].a - 1
+	}
+	if startB == -1 {
+		startB = edits[0].b - 1
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(startA, countA), hunkRange(startB, countB))
+	for _, e := range edits {
+		switch e.kind {
+		case editEqual:
+			writeDiffLine(sb, ' ', linesA[e.a])
+		case editDelete:
+			writeDiffLine(sb, '-', linesA[e.a])
+		case editInsert:
+			writeDiffLine(sb, '+', linesB[e.b])
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeDiffLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines splits s after each newline, keeping the newline characters
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// myers computes a shortest edit script from a to b (E. Myers, "An O(ND) Difference Algorithm and Its Variations")
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	var d int
+	for d = 0; d <= maxD; d++ {
+		snapshot := make([]int, 2*d+1)
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if k == n-m && x >= n {
+				done = true
+			}
+		}
+		for k := -d; k <= d; k++ {
+			snapshot[k+d] = v[offset+k]
+		}
+		trace = append(trace, snapshot)
+		if done {
+			break
+		}
+	}
+
+	// backtrack
+	edits := make([]edit, 0, n+m)
+	x, y := n, m
+	for ; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{editEqual, x, y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{editInsert, x, y})
+		} else {
+			x--
+			edits = append(edits, edit{editDelete, x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{editEqual, x, y})
+	}
+
+	// reverse
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
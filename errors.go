@@ -0,0 +1,342 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// GenerationError is returned when a template fails to execute. It locates the failure in the original
+// template sources, even when several templates were concatenated (see GenerateFromString).
+type GenerationError struct {
+	Output   string // output file
+	Template string // template file, or "templates[i]" for the i-th string given to GenerateFromString
+	Line     int    // line in Template
+	Action   string // failing action, as written in the template
+	DataPath string // data being evaluated, from the data of the executing template; range elements are written "[]"
+	Excerpt  string // template lines around Line
+	Err      error  // error returned by text/template
+
+	msg string // Err without its text/template location prefix
+}
+
+func (e *GenerationError) Error() string {
+	var sb strings.Builder
+	if e.Template == "" {
+		fmt.Fprintf(&sb, "%s: %v", filepath.Clean(e.Output), e.Err)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "%s:%d: %s", e.Template, e.Line, e.msg)
+	if e.Action != "" {
+		fmt.Fprintf(&sb, "\n\taction: %s", e.Action)
+	}
+	if e.DataPath != "" {
+		fmt.Fprintf(&sb, "\n\tdata:   %s", e.DataPath)
+	}
+	fmt.Fprintf(&sb, "\n\toutput: %s", filepath.Clean(e.Output))
+	if e.Excerpt != "" {
+		sb.WriteString("\n")
+		sb.WriteString(e.Excerpt)
+	}
+	return sb.String()
+}
+
+func (e *GenerationError) Unwrap() error {
+	return e.Err
+}
+
+// EntryError is the error of one Entry of a batch generation
+type EntryError struct {
+	Entry Entry
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	var context []string
+	if len(e.Entry.Templates) != 0 {
+		context = append(context, "templates: "+strings.Join(e.Entry.Templates, ", "))
+	}
+	if e.Entry.BuildTag != "" {
+		context = append(context, "build tag: "+e.Entry.BuildTag)
+	}
+	if len(context) == 0 {
+		return fmt.Sprintf("%s: %v", filepath.Clean(e.Entry.File), e.Err)
+	}
+	return fmt.Sprintf("%s [%s]: %v", filepath.Clean(e.Entry.File), strings.Join(context, "; "), e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// execErrorRegexp matches the errors text/template reports for a node: location, template name, context, message
+var execErrorRegexp = regexp.MustCompile(`^template: (.*):(\d+):(\d+): executing ("(?:[^"\\]|\\.)*") at <(?s:.*?)>: (?s:(.*))$`)
+
+// excerptContext is the number of lines shown before and after the failing line in GenerationError.Excerpt
+const excerptContext = 2
+
+// generationError wraps an error returned by tmpl.Execute into a *GenerationError
+func (b *Bavard) generationError(output string, tmpl *template.Template, err error) error {
+	gErr := &GenerationError{Output: output, Err: err, msg: err.Error()}
+
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) || b.sourceMap == nil {
+		return gErr
+	}
+	// errors in templates called as functions (see tmplfunc) are wrapped; locate the innermost one
+	for {
+		var inner template.ExecError
+		if !errors.As(execErr.Err, &inner) {
+			break
+		}
+		execErr = inner
+	}
+	m := execErrorRegexp.FindStringSubmatch(execErr.Err.Error())
+	if m == nil {
+		return gErr
+	}
+	name, executing, msg := m[1], m[4], m[5]
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+	if s, err := strconv.Unquote(executing); err == nil {
+		executing = s
+	}
+	gErr.msg = msg
+
+	sources := b.sourceMap.sources
+	gErr.Template, gErr.Line = sources.resolve(name, line)
+	if text, ok := sources.text(name); ok {
+		if offset, ok := lineOffset(text, line); ok {
+			pos := offset + col
+			gErr.Action = enclosingAction(text, pos)
+			if t := tmpl.Lookup(executing); t != nil && t.Tree != nil {
+				gErr.DataPath = dataPath(t.Tree.Root, parse.Pos(pos))
+			}
+		}
+	}
+	if text, ok := sources.text(gErr.Template); ok {
+		gErr.Excerpt = excerpt(text, gErr.Line)
+	}
+	return gErr
+}
+
+// lineOffset returns the offset of the first byte of line (1-based) in text
+func lineOffset(text string, line int) (int, bool) {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		offset += i + 1
+	}
+	return offset, true
+}
+
+// enclosingAction returns the {{...}} action of text containing pos
+func enclosingAction(text string, pos int) string {
+	if pos > len(text) {
+		return ""
+	}
+	start := strings.LastIndex(text[:pos], "{{")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(text[start:], "}}")
+	if end < 0 {
+		return ""
+	}
+	return text[start : start+end+2]
+}
+
+// excerpt returns the lines of text around line, the failing line being marked with '>'
+func excerpt(text string, line int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	first, last := max(line-excerptContext, 1), min(line+excerptContext, len(lines))
+	width := len(strconv.Itoa(last))
+	var sb strings.Builder
+	for l := first; l <= last; l++ {
+		marker := " "
+		if l == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "\t%s %*d | %s\n", marker, width, l, strings.TrimRight(lines[l-1], " \t\r"))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// dataScope tracks, while walking a template tree, the data path of dot and of the declared variables
+type dataScope struct {
+	dot  string
+	vars map[string]string
+}
+
+func (s dataScope) with(dot string) dataScope {
+	vars := make(map[string]string, len(s.vars))
+	for k, v := range s.vars {
+		vars[k] = v
+	}
+	return dataScope{dot: dot, vars: vars}
+}
+
+// resolve returns the data path of a pipeline argument in this scope
+func (s dataScope) resolve(n parse.Node) string {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return s.dot
+	case *parse.FieldNode:
+		return joinDataPath(s.dot, n.Ident)
+	case *parse.VariableNode:
+		base, ok := s.vars[n.Ident[0]]
+		if !ok {
+			base = n.Ident[0]
+		}
+		return joinDataPath(base, n.Ident[1:])
+	case *parse.ChainNode:
+		return joinDataPath(s.resolve(n.Node), n.Field)
+	case *parse.PipeNode:
+		if len(n.Cmds) == 1 && len(n.Cmds[0].Args) == 1 {
+			return s.resolve(n.Cmds[0].Args[0])
+		}
+		return "(" + n.String() + ")"
+	case *parse.CommandNode:
+		if len(n.Args) == 1 {
+			return s.resolve(n.Args[0])
+		}
+		return "(" + n.String() + ")"
+	default:
+		return "(" + n.String() + ")"
+	}
+}
+
+func joinDataPath(base string, fields []string) string {
+	if len(fields) == 0 {
+		return base
+	}
+	if base == "." {
+		base = ""
+	}
+	return base + "." + strings.Join(fields, ".")
+}
+
+// dataPath returns the data path of the node at pos in the tree rooted at root
+func dataPath(root *parse.ListNode, pos parse.Pos) string {
+	path, _ := dataPathIn(root, pos, dataScope{dot: ".", vars: map[string]string{"$": "."}})
+	return path
+}
+
+func dataPathIn(n parse.Node, pos parse.Pos, scope dataScope) (string, bool) {
+	switch n := n.(type) {
+	case nil:
+		return "", false
+	case *parse.ListNode:
+		if n == nil {
+			return "", false
+		}
+		for _, c := range n.Nodes {
+			if path, ok := dataPathIn(c, pos, scope); ok {
+				return path, true
+			}
+		}
+		return "", false
+	case *parse.ActionNode:
+		if isMark(n) {
+			return "", false
+		}
+		path, ok := dataPathIn(n.Pipe, pos, scope)
+		if !ok && n.Pipe != nil {
+			// variables declared by the action are visible in the rest of the list
+			for _, v := range n.Pipe.Decl {
+				scope.vars[v.Ident[0]] = scope.resolve(n.Pipe)
+			}
+		}
+		return path, ok
+	case *parse.PipeNode:
+		if n == nil {
+			return "", false
+		}
+		if n.Position() == pos {
+			return scope.resolve(n), true
+		}
+		for _, c := range n.Cmds {
+			if path, ok := dataPathIn(c, pos, scope); ok {
+				return path, true
+			}
+		}
+		return "", false
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if path, ok := dataPathIn(a, pos, scope); ok {
+				return path, true
+			}
+		}
+		if n.Position() == pos {
+			return scope.resolve(n), true
+		}
+		return "", false
+	case *parse.ChainNode:
+		if path, ok := dataPathIn(n.Node, pos, scope); ok {
+			return path, true
+		}
+		if n.Position() == pos {
+			return scope.resolve(n), true
+		}
+		return "", false
+	case *parse.IfNode:
+		return dataPathInBranch(&n.BranchNode, pos, scope)
+	case *parse.WithNode:
+		return dataPathInBranch(&n.BranchNode, pos, scope)
+	case *parse.RangeNode:
+		return dataPathInBranch(&n.BranchNode, pos, scope)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			return dataPathIn(n.Pipe, pos, scope)
+		}
+		return "", false
+	default:
+		if n.Position() == pos {
+			return scope.resolve(n), true
+		}
+		return "", false
+	}
+}
+
+func dataPathInBranch(n *parse.BranchNode, pos parse.Pos, scope dataScope) (string, bool) {
+	if path, ok := dataPathIn(n.Pipe, pos, scope); ok {
+		return path, true
+	}
+	inner := scope.with(scope.dot)
+	switch n.NodeType {
+	case parse.NodeWith:
+		inner.dot = scope.resolve(n.Pipe)
+		for _, v := range n.Pipe.Decl {
+			inner.vars[v.Ident[0]] = inner.dot
+		}
+	case parse.NodeRange:
+		inner.dot = scope.resolve(n.Pipe) + "[]"
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner.vars[n.Pipe.Decl[0].Ident[0]] = inner.dot
+		case 2:
+			inner.vars[n.Pipe.Decl[0].Ident[0]] = "(index)"
+			inner.vars[n.Pipe.Decl[1].Ident[0]] = inner.dot
+		}
+	default:
+		for _, v := range n.Pipe.Decl {
+			inner.vars[v.Ident[0]] = scope.resolve(n.Pipe)
+		}
+	}
+	if path, ok := dataPathIn(n.List, pos, inner); ok {
+		return path, true
+	}
+	// the else branch of a range or with runs with the outer dot
+	return dataPathIn(n.ElseList, pos, scope.with(scope.dot))
+}
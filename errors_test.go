@@ -0,0 +1,39 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerationError(t *testing.T) {
+	type field struct {
+		Name string
+		Sub  *field
+	}
+	data := struct{ Fields []field }{Fields: []field{{Name: "a"}}}
+	output := filepath.Join(t.TempDir(), "out.go")
+
+	templates := []string{
+		"// header\n",
+		"{{range $i, $f := .Fields}}\n// {{$f.Name}}\n{{ $f.Sub.Name }}\n{{end}}\n",
+	}
+	err := GenerateFromString(output, templates, data, Package("test"), Verbose(false))
+	var gErr *GenerationError
+	if !errors.As(err, &gErr) {
+		t.Fatalf("expected a *GenerationError, got %v", err)
+	}
+	if gErr.Output != output || gErr.Template != "templates[1]" || gErr.Line != 3 {
+		t.Fatalf("unexpected location %s:%d", gErr.Template, gErr.Line)
+	}
+	if gErr.Action != "{{ $f.Sub.Name }}" || gErr.DataPath != ".Fields[].Sub.Name" {
+		t.Fatalf("unexpected action %q or data path %q", gErr.Action, gErr.DataPath)
+	}
+	if !strings.Contains(gErr.Excerpt, "> 3 | {{ $f.Sub.Name }}") {
+		t.Fatalf("unexpected excerpt:\n%s", gErr.Excerpt)
+	}
+}
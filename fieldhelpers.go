@@ -0,0 +1,188 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// finite field template helpers. They take the modulus q last, as a big.Int, *big.Int or string (see
+// bigHelpers), and derive the constants the field arithmetic needs, so that templates don't depend on
+// precomputed values.
+func fieldHelpers() map[string]interface{} {
+	return map[string]interface{}{
+		"fieldWords":            fieldWords,
+		"frobeniusCoefficients": frobeniusCoefficients,
+		"legendreExponent":      legendreExponent,
+		"montWords":             montWords,
+		"rootOfUnity":           rootOfUnity,
+		"sqrtExponent":          sqrtExponent,
+		"toMont":                toMont,
+		"twoAdicity":            twoAdicity,
+	}
+}
+
+// toPrime returns q, which must be an odd prime
+func toPrime(q interface{}) (*big.Int, error) {
+	p, err := toModulus(q)
+	if err != nil {
+		return nil, err
+	}
+	if !p.ProbablyPrime(20) {
+		return nil, fmt.Errorf("modulus %s is not prime", p)
+	}
+	return p, nil
+}
+
+// splitTwoAdic returns s and t such that q-1 = 2ˢ·t with t odd
+func splitTwoAdic(q *big.Int) (s int, t *big.Int) {
+	t = new(big.Int).Sub(q, big.NewInt(1))
+	s = int(t.TrailingZeroBits())
+	return s, t.Rsh(t, uint(s))
+}
+
+// twoAdicity returns the largest s such that 2ˢ divides q-1
+func twoAdicity(q interface{}) (int, error) {
+	p, err := toPrime(q)
+	if err != nil {
+		return 0, err
+	}
+	s, _ := splitTwoAdic(p)
+	return s, nil
+}
+
+// rootOfUnity returns a primitive 2ˢ-th root of unity modulo q, with s the 2-adicity of q: gᵗ for g the
+// smallest quadratic non-residue, and q-1 = 2ˢ·t
+func rootOfUnity(q interface{}) (*big.Int, error) {
+	p, err := toPrime(q)
+	if err != nil {
+		return nil, err
+	}
+	s, t := splitTwoAdic(p)
+	g := nonResidue(p)
+	if s == 0 || g == nil {
+		return nil, fmt.Errorf("no non-trivial 2-adic root of unity modulo %s", p)
+	}
+	return g.Exp(g, t, p), nil
+}
+
+// nonResidue returns the smallest quadratic non-residue modulo the odd prime p
+func nonResidue(p *big.Int) *big.Int {
+	for g := big.NewInt(2); g.Cmp(p) < 0; g.Add(g, big.NewInt(1)) {
+		if big.Jacobi(g, p) == -1 {
+			return g
+		}
+	}
+	return nil
+}
+
+// legendreExponent returns (q-1)/2: x^((q-1)/2) is the Legendre symbol of x
+func legendreExponent(q interface{}) (*big.Int, error) {
+	p, err := toPrime(q)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).Sub(p, big.NewInt(1))
+	return r.Rsh(r, 1), nil
+}
+
+// sqrtExponent returns the exponent used to compute square roots modulo q: (q+1)/4 if q ≡ 3 mod 4, so that
+// x^((q+1)/4) is a square root of x, and otherwise (t-1)/2 for q-1 = 2ˢ·t, as used by Tonelli-Shanks
+func sqrtExponent(q interface{}) (*big.Int, error) {
+	p, err := toPrime(q)
+	if err != nil {
+		return nil, err
+	}
+	if p.Bit(1) == 1 {
+		r := new(big.Int).Add(p, big.NewInt(1))
+		return r.Rsh(r, 2), nil
+	}
+	_, t := splitTwoAdic(p)
+	return t.Rsh(t, 1), nil
+}
+
+// frobeniusCoefficients returns the coefficients of the Frobenius map x ↦ x^q on Fq[u]/(uᵏ - β): since
+// (uⁱ)^q = β^(i(q-1)/k)·uⁱ, the i-th coefficient is β^(i(q-1)/k) mod q, for i in [0, k). k must divide q-1.
+func frobeniusCoefficients(beta, k, q interface{}) ([]*big.Int, error) {
+	p, err := toPrime(q)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBig(beta)
+	if err != nil {
+		return nil, err
+	}
+	degree, err := toInt64(k)
+	if err != nil {
+		return nil, err
+	}
+	if degree <= 0 {
+		return nil, fmt.Errorf("invalid extension degree %d", degree)
+	}
+	e, rem := new(big.Int).QuoRem(new(big.Int).Sub(p, big.NewInt(1)), big.NewInt(degree), new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, fmt.Errorf("extension degree %d does not divide %s-1", degree, p)
+	}
+	gamma := new(big.Int).Exp(b.Mod(b, p), e, p)
+	r := make([]*big.Int, degree)
+	r[0] = big.NewInt(1)
+	for i := 1; i < len(r); i++ {
+		r[i] = new(big.Int).Mul(r[i-1], gamma)
+		r[i].Mod(r[i], p)
+	}
+	return r, nil
+}
+
+// toMont returns x·R mod q, the Montgomery form of x (see montgomeryR)
+func toMont(x, q interface{}) (*big.Int, error) {
+	v, err := toBig(x)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toModulus(q)
+	if err != nil {
+		return nil, err
+	}
+	v.Lsh(v, uint(64*nbWords(m)))
+	return v.Mod(v, m), nil
+}
+
+// fieldWords returns x mod q as a list of nbWords(q) 64-bit words, least significant first, padded with zeros
+// (for instance "1, 0, 0, 0"), to be used in an array literal
+func fieldWords(x, q interface{}) (string, error) {
+	v, err := toBig(x)
+	if err != nil {
+		return "", err
+	}
+	m, err := toModulus(q)
+	if err != nil {
+		return "", err
+	}
+	return paddedWords(v.Mod(v, m), nbWords(m)), nil
+}
+
+// montWords returns the Montgomery form of x modulo q, formatted as fieldWords
+func montWords(x, q interface{}) (string, error) {
+	v, err := toMont(x, q)
+	if err != nil {
+		return "", err
+	}
+	m, _ := toModulus(q)
+	return paddedWords(v, nbWords(m)), nil
+}
+
+// paddedWords writes the 64-bit words of v with WriteBigIntAsUint64Slice, padded with zeros to n words
+func paddedWords(v *big.Int, n int) string {
+	builder := StringBuilderPool.Get().(*strings.Builder)
+	builder.Reset()
+	defer StringBuilderPool.Put(builder)
+
+	WriteBigIntAsUint64Slice(builder, v)
+	for i := max(nbWords(v), 1); i < n; i++ {
+		builder.WriteString(", 0")
+	}
+	return builder.String()
+}
@@ -0,0 +1,57 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFieldHelpers(t *testing.T) {
+	data := map[string]string{
+		"q": "21888242871839275222246405745257275088696311157297823662689037894645226208583", // bn254 base field, q ≡ 3 mod 4
+		"r": "21888242871839275222246405745257275088548364400416034343698204186575808495617", // bn254 scalar field, 2-adicity 28
+	}
+	tmpl := `s = {{twoAdicity .r}}
+w = {{$w := rootOfUnity .r}}{{bigExp $w (bigExp 2 28) .r}} {{eq (bigExp $w (bigExp 2 27) .r).String (bigAdd .r -1).String}}
+legendre = {{legendreExponent .r}}
+sqrtR = {{sqrtExponent .r}}
+sqrtQ = {{sqrtExponent .q}}
+frobenius = {{printList (frobeniusCoefficients 5 3 .r)}}
+one = [4]uint64{ {{- montWords 1 .q}}}
+two = [4]uint64{ {{- fieldWords 2 .q}}}
+mont = {{eq (toMont 1 .q).String (montgomeryR .q).String}}
+`
+	want := `s = 28
+w = 1 true
+legendre = 10944121435919637611123202872628637544274182200208017171849102093287904247808
+sqrtR = 40770029410420498293352137776570907027550720424234931066070132305055
+sqrtQ = 5472060717959818805561601436314318772174077789324455915672259473661306552146
+frobenius = 1, 4407920970296243842393367215006156084916469457145843978461, 21888242871839275217838484774961031246154997185409878258781734729429964517155
+one = [4]uint64{15230403791020821917, 754611498739239741, 7381016538464732716, 1011752739694698287}
+two = [4]uint64{2, 0, 0, 0}
+mont = true
+`
+	output := filepath.Join(t.TempDir(), "x.txt")
+	if err := GenerateFromString(output, []string{tmpl}, data, Verbose(false), GeneratedBy("test")); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(output)
+	got := strings.TrimPrefix(string(content), "// Code generated by test DO NOT EDIT\n\n")
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, tmpl := range []string{
+		`{{twoAdicity 15}}`,
+		`{{frobeniusCoefficients 5 7 .r}}`,
+		`{{rootOfUnity 2}}`,
+	} {
+		if err := GenerateFromString(output, []string{tmpl}, data, Verbose(false)); err == nil {
+			t.Fatalf("%s: expected an error", tmpl)
+		}
+	}
+}
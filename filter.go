@@ -0,0 +1,216 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OutputFilter selects the outputs to generate, see ParseFilter. A nil *OutputFilter selects all outputs.
+type OutputFilter struct {
+	include, exclude []filterPattern
+}
+
+type filterPattern struct {
+	on    filterOn
+	match func(string) bool
+}
+
+// filterOn is the attribute of an output a pattern is matched against
+type filterOn int
+
+const (
+	filterOutput filterOn = iota
+	filterTag
+	filterPackage
+)
+
+// ParseFilter parses a comma-separated list of patterns, as found in the BAVARD_FILTER environment variable.
+// An output is generated if it matches at least one of the include patterns (or if there are none), and none
+// of the exclude patterns, which are prefixed by "!". Patterns are one of:
+//
+//	re:<regexp>  regular expression matching the output path
+//	tag:<glob>   glob matching one of the tags of the build constraint of the output, negated or not
+//	pkg:<glob>   glob matching the package name of the output
+//	<glob>       glob (with *, ? or [) matching the output path, or its base name
+//	<substring>  substring of the output path
+//
+// Paths are slash-separated, whatever the OS, and matched both as given and cleaned.
+func ParseFilter(s string) (*OutputFilter, error) {
+	f := new(OutputFilter)
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		exclude := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		if p == "" {
+			continue
+		}
+		pattern, err := parseFilterPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			f.exclude = append(f.exclude, pattern)
+		} else {
+			f.include = append(f.include, pattern)
+		}
+	}
+	return f, nil
+}
+
+func parseFilterPattern(p string) (filterPattern, error) {
+	if expr, ok := strings.CutPrefix(p, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return filterPattern{}, fmt.Errorf("invalid filter %q: %w", p, err)
+		}
+		return filterPattern{on: filterOutput, match: re.MatchString}, nil
+	}
+	on, glob := filterOutput, p
+	if tag, ok := strings.CutPrefix(p, "tag:"); ok {
+		on, glob = filterTag, tag
+	} else if pkg, ok := strings.CutPrefix(p, "pkg:"); ok {
+		on, glob = filterPackage, pkg
+	} else if !strings.ContainsAny(p, "*?[") {
+		return filterPattern{on: filterOutput, match: func(s string) bool { return strings.Contains(s, p) }}, nil
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		return filterPattern{}, fmt.Errorf("invalid filter %q: %w", p, err)
+	}
+	match := func(s string) bool {
+		ok, _ := path.Match(glob, s)
+		return ok
+	}
+	if on == filterOutput {
+		return filterPattern{on: on, match: func(s string) bool { return match(s) || match(path.Base(s)) }}, nil
+	}
+	return filterPattern{on: on, match: match}, nil
+}
+
+// Match reports whether output, in package pkg and with the build constraint buildTag, is selected by f
+func (f *OutputFilter) Match(output, pkg string, buildTag BuildConstraint) bool {
+	if f == nil {
+		return true
+	}
+	// outputs are matched as given and cleaned, so that "./fp" matches "./fp/element.go" as well as "fp/element.go"
+	raw, output := filepath.ToSlash(output), filepath.ToSlash(filepath.Clean(output))
+	var tags []string
+	if buildTag.expr != nil {
+		tags = constraintTags(buildTag.expr)
+	}
+	matches := func(p filterPattern) bool {
+		switch p.on {
+		case filterTag:
+			for _, tag := range tags {
+				if p.match(tag) {
+					return true
+				}
+			}
+			return false
+		case filterPackage:
+			return p.match(pkg)
+		}
+		return p.match(raw) || p.match(output)
+	}
+
+	for _, p := range f.exclude {
+		if matches(p) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// envFilter returns the filter set by the BAVARD_FILTER environment variable
+func envFilter() (*OutputFilter, error) {
+	s := os.Getenv(EnvFilter)
+	if s == "" {
+		return nil, nil
+	}
+	f, err := ParseFilter(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvFilter, err)
+	}
+	return f, nil
+}
+
+// Filter returns a bavard option generating the output only if it is selected by filter, instead of the
+// BAVARD_FILTER environment variable. See ParseFilter for the syntax.
+func Filter(filter string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		f, err := ParseFilter(filter)
+		if err != nil {
+			return err
+		}
+		b.filter, b.filterSet = f, true
+		return nil
+	}
+}
+
+// DryRun returns a bavard option listing the outputs which would be generated to w, one path per line,
+// instead of generating them. Dry run mode is also enabled by setting the BAVARD_DRY_RUN environment variable
+// to a true value ("1", "true"...), the outputs are then listed on the standard output.
+func DryRun(w io.Writer) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.dryRun = w
+		return nil
+	}
+}
+
+// dryRunMu serializes the lines listed in dry run mode, as entries of a batch are generated concurrently
+var dryRunMu sync.Mutex
+
+// configFilter sets the filter and dry run mode from the environment, unless set by options
+func (b *Bavard) configFilter() error {
+	if !b.filterSet {
+		f, err := envFilter()
+		if err != nil {
+			return err
+		}
+		b.filter = f
+	}
+	if s := os.Getenv(EnvDryRun); b.dryRun == nil && s != "" {
+		dryRun, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", EnvDryRun, s, err)
+		}
+		if dryRun {
+			b.dryRun = os.Stdout
+		}
+	}
+	return nil
+}
+
+// selected reports whether output must be generated. In dry run mode, the selected outputs are listed
+// instead.
+func (b *Bavard) selected(output string) (bool, error) {
+	b.skipped = true
+	if !b.filter.Match(output, b.packageName, b.buildTag) {
+		return false, nil
+	}
+	if b.dryRun != nil {
+		dryRunMu.Lock()
+		defer dryRunMu.Unlock()
+		_, err := fmt.Fprintln(b.dryRun, filepath.Clean(output))
+		return false, err
+	}
+	b.skipped = false
+	return true, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	amd64, _ := ParseBuildConstraint("amd64 && !purego")
+	for _, tt := range []struct {
+		filter string
+		want   []bool // x/a.go, x/a_amd64.s (amd64 && !purego), y/b_test.go (package other)
+	}{
+		{"", []bool{true, true, true}},
+		{"a", []bool{true, true, false}},
+		{"*.s", []bool{false, true, false}},
+		{"x/*", []bool{true, true, false}},
+		{"re:_(amd64|test)\\.", []bool{false, true, true}},
+		{"!*_test.go", []bool{true, true, false}},
+		{"x/, !re:\\.s$", []bool{true, false, false}},
+		{"tag:purego", []bool{false, true, false}},
+		{"!tag:amd*", []bool{true, false, true}},
+		{"pkg:oth*", []bool{false, false, true}},
+		{"pkg:test, tag:amd64", []bool{true, true, false}},
+	} {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []bool{
+			f.Match("x/a.go", "test", BuildConstraint{}),
+			f.Match(filepath.Join("x", "a_amd64.s"), "test", amd64),
+			f.Match("y/b_test.go", "other", BuildConstraint{}),
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("filter %q: got %v, want %v", tt.filter, got, tt.want)
+			}
+		}
+	}
+	for _, filter := range []string{"re:(", "pkg:[", "x/[a-"} {
+		if _, err := ParseFilter(filter); err == nil {
+			t.Fatalf("expected an error for filter %q", filter)
+		}
+	}
+	if f, _ := ParseFilter("./fp"); !f.Match("./fp/element.go", "", BuildConstraint{}) {
+		t.Fatal("filter ./fp doesn't match ./fp/element.go")
+	}
+
+	dir := t.TempDir()
+	// outputs filtered out don't get a header: a build tag contradicting the file name isn't reported
+	err := GenerateFromString(filepath.Join(dir, "x_arm64.go"), []string{"\n"}, nil, Verbose(false), BuildTag("amd64"), Filter("!arm64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTemplate(t, dir, "x.tmpl", "var x = 1\n")
+	entries := []Entry{
+		{File: filepath.Join(dir, "a.go"), Templates: []string{"x.tmpl"}},
+		{File: filepath.Join(dir, "b.go"), Templates: []string{"x.tmpl"}, Package: "other"},
+		{File: filepath.Join(dir, "c_amd64.go"), Templates: []string{"x.tmpl"}, BuildTag: "!purego"},
+	}
+	var listed bytes.Buffer
+	opts := []func(*Bavard) error{Verbose(false), Filter("!pkg:other"), DryRun(&listed)}
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", MaxParallelism(1))
+	if err := bgen.GenerateWithOptions(nil, "test", dir, opts, entries...); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "a.go") + "\n" + filepath.Join(dir, "c_amd64.go") + "\n"; listed.String() != want {
+		t.Fatalf("dry run listed:\n%s\nwant:\n%s", listed.String(), want)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(e.File); err == nil {
+			t.Fatalf("%s generated in dry run mode", e.File)
+		}
+	}
+
+	t.Setenv(EnvFilter, "tag:purego")
+	if err := bgen.GenerateWithOptions(nil, "test", dir, []func(*Bavard) error{Verbose(false)}, entries...); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entries {
+		if _, err := os.Stat(e.File); (err == nil) != (i == 2) {
+			t.Fatalf("%s: unexpected generation with %s=%q (%v)", e.File, EnvFilter, os.Getenv(EnvFilter), err)
+		}
+	}
+	t.Setenv(EnvFilter, "re:(")
+	if err := GenerateFromString(filepath.Join(dir, "d.go"), []string{"\n"}, nil, Verbose(false)); err == nil {
+		t.Fatalf("expected an error for an invalid %s", EnvFilter)
+	}
+	t.Setenv(EnvFilter, "")
+	t.Setenv(EnvDryRun, "1")
+	if err := GenerateFromString(filepath.Join(dir, "d.go"), []string{"\n"}, nil, Verbose(false), DryRun(io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "d.go")); err == nil {
+		t.Fatal("d.go generated in dry run mode")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+)
+
+// FormatError is returned when the generated code can't be formatted, which usually means the templates
+// produced invalid Go code. The position of the error in the generated code is mapped back to the template
+// line that produced it.
+type FormatError struct {
+	Output       string // output file
+	Line, Column int    // position in the generated (unformatted) code
+	Source       string // generated line at Line
+	Template     string // template which produced the line; empty if it comes from bavard (header, package clause)
+	TemplateLine int
+	Err          error
+}
+
+func (e *FormatError) Error() string {
+	msg := fmt.Sprintf("%s:%d:%d: %v", filepath.Clean(e.Output), e.Line, e.Column, e.Err)
+	if e.Source != "" {
+		msg += "\n\t" + e.Source
+	}
+	if e.Template != "" {
+		msg += fmt.Sprintf("\n\tgenerated by %s:%d", e.Template, e.TemplateLine)
+	}
+	return msg
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// gofmt formats src the way "gofmt -s" does
+func gofmt(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	ast.SortImports(fset, file)
+	simplify(file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatError wraps a formatter error, locating it in the generated code and in the templates
+func (b *Bavard) formatError(output string, src []byte, err error) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return fmt.Errorf("%s: %w", filepath.Clean(output), err)
+	}
+	pos := list[0].Pos
+	fErr := &FormatError{
+		Output: output,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Err:    errors.New(list[0].Msg),
+	}
+	if pos.Line > 0 {
+		lines := bytes.Split(src, []byte{'\n'})
+		if pos.Line <= len(lines) {
+			fErr.Source = string(bytes.TrimSpace(lines[pos.Line-1]))
+		}
+	}
+	fErr.Template, fErr.TemplateLine = b.sourceMap.lookup(src, pos.Offset)
+	return fErr
+}
@@ -0,0 +1,102 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.go")
+	opts := []func(*Bavard) error{Package("test"), Verbose(false), Format(true), Import(true)}
+
+	const tmpl = `
+func f(s []int) []int {
+    fmt.Println("x")
+	return s[1:len(s)]
+}
+`
+	if err := GenerateFromString(output, []string{tmpl}, nil, opts...); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(output)
+	if !strings.Contains(string(got), "import \"fmt\"\n") || !strings.Contains(string(got), "\treturn s[1:]\n") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// standard library imports are resolved without the go command
+	t.Setenv("PATH", "")
+	const std = `
+import (
+	"os"
+	"math/bits"
+)
+
+func g(x uint64) string {
+	return strings.Repeat("x", bits.OnesCount64(x)) + filepath.Base("a/b")
+}
+`
+	if err := GenerateFromString(output, []string{std}, nil, opts...); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = os.ReadFile(output)
+	const imports = "import (\n\t\"math/bits\"\n\t\"path/filepath\"\n\t\"strings\"\n)\n"
+	if !strings.Contains(string(got), imports) {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// other packages need it
+	err := GenerateFromString(output, []string{"func h() { fr.Foo() }\n"}, nil, opts...)
+	if err == nil || !strings.Contains(err.Error(), "requires the go command") {
+		t.Fatalf("expected an error about the go command, got %v", err)
+	}
+
+	// declarations of the other files of the package are read from the output sink
+	mem := NewMemorySink()
+	memOpts := append(opts, Output(mem))
+	if err := GenerateFromString("gen/a.go", []string{"var bits = struct{ Len int }{}\n"}, nil, memOpts...); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateFromString("gen/b.go", []string{"var n = bits.Len\n"}, nil, memOpts...); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(mem.Files()["gen/b.go"]); strings.Contains(got, "math/bits") {
+		t.Fatalf("package level name taken for a package:\n%s", got)
+	}
+}
+
+func TestFormatErrorLocation(t *testing.T) {
+	dir := t.TempDir()
+	tmplFile := filepath.Join(dir, "f.go.tmpl")
+	writeTemplate(t, dir, "f.go.tmpl", `func f() int {
+	{{- range $i := .}}
+	x{{$i}} := {{$i}}
+	{{- end}}
+	return x0 +
+}
+`)
+	output := filepath.Join(dir, "out.go")
+	err := GenerateFromFiles(output, []string{tmplFile}, []int{0, 1}, Package("test"), Verbose(false), Format(true))
+	var fErr *FormatError
+	if !errors.As(err, &fErr) {
+		t.Fatalf("expected a *FormatError, got %v", err)
+	}
+	if fErr.Template != tmplFile || fErr.TemplateLine != 6 {
+		t.Fatalf("expected error to be located at %s:6, got %s:%d (%v)", tmplFile, fErr.Template, fErr.TemplateLine, err)
+	}
+
+	// same template given as strings: the location refers to the part
+	err = GenerateFromString(output, []string{"func f() int {\n", "\treturn 1 +\n}\n"}, nil, Package("test"), Verbose(false), Format(true))
+	if !errors.As(err, &fErr) {
+		t.Fatalf("expected a *FormatError, got %v", err)
+	}
+	if fErr.Template != "templates[1]" || fErr.TemplateLine != 2 {
+		t.Fatalf("expected error to be located at templates[1]:2, got %s:%d (%v)", fErr.Template, fErr.TemplateLine, err)
+	}
+}
@@ -1,5 +1,13 @@
 module github.com/consensys/bavard
 
-go 1.22
+go 1.22.0
 
-require rsc.io/tmplfunc v0.0.3
+require (
+	golang.org/x/tools v0.30.0
+	rsc.io/tmplfunc v0.0.3
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
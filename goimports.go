@@ -0,0 +1,331 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+// goimports adds missing and removes unused imports in src. Standard library imports are resolved in-process,
+// from the sources of GOROOT; the go command is only needed (by golang.org/x/tools/imports) when the file refers
+// to a package outside of the standard library that it doesn't import, or imports one that may be unused.
+func goimports(sink Sink, filename string, src []byte) ([]byte, error) {
+	opts := &imports.Options{
+		Comments:  true,
+		TabIndent: true,
+		TabWidth:  8,
+	}
+	fixed, ok, err := fixStdImports(sink, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		opts.FormatOnly = true
+		return imports.Process(filename, fixed, opts)
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, fmt.Errorf("resolving imports of packages outside of the standard library requires the go command: %w", err)
+	}
+	return imports.Process(filename, src, opts)
+}
+
+// fixStdImports adds the missing standard library imports of src and removes the unused ones. Names declared by
+// the other files of the package, read from sink, are not taken for packages. ok is false if the imports can't be
+// fixed without the go command: src refers to a package which isn't imported and isn't in the standard library,
+// or an import outside of the standard library doesn't seem to be used.
+func fixStdImports(sink Sink, filename string, src []byte) (fixed []byte, ok bool, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// selectors on identifiers that aren't declared in the file nor in the rest of its package
+	declared := packageDeclarations(sink, filename, file.Name.Name)
+	refs := map[string]map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, isSel := n.(*ast.SelectorExpr)
+		if !isSel {
+			return true
+		}
+		x, isIdent := sel.X.(*ast.Ident)
+		if !isIdent || x.Obj != nil || declared[x.Name] {
+			return true
+		}
+		if refs[x.Name] == nil {
+			refs[x.Name] = map[string]bool{}
+		}
+		refs[x.Name][sel.Sel.Name] = true
+		return true
+	})
+
+	changed := false
+	imported := map[string]bool{}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := assumedPackageName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch name {
+		case "_", ".":
+			continue
+		}
+		if importPath == "C" {
+			continue
+		}
+		imported[name] = true
+		if refs[name] != nil {
+			continue
+		}
+		if !isStdPath(importPath) {
+			return nil, false, nil
+		}
+		if spec.Name != nil {
+			astutil.DeleteNamedImport(fset, file, spec.Name.Name, importPath)
+		} else {
+			astutil.DeleteImport(fset, file, importPath)
+		}
+		changed = true
+	}
+
+	missing := make([]string, 0, len(refs))
+	for name := range refs {
+		if !imported[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		importPath := stdPackage(name, refs[name])
+		if importPath == "" {
+			return nil, false, nil
+		}
+		if path.Base(importPath) == name {
+			astutil.AddImport(fset, file, importPath)
+		} else {
+			astutil.AddNamedImport(fset, file, name, importPath)
+		}
+		changed = true
+	}
+
+	if !changed {
+		return src, true, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
+
+// packageDeclarations returns the package level names declared in the other Go files of the package in the
+// directory of filename, as found in sink: the file system for a FileSink, the files written for a sink
+// implementing fs.ReadDirFS (MemorySink, OverlaySink, ArchiveSink). Other sinks can't be listed.
+func packageDeclarations(sink Sink, filename, pkg string) map[string]bool {
+	declared := map[string]bool{}
+	dir := filepath.Dir(filename)
+	var entries []fs.DirEntry
+	switch s := sink.(type) {
+	case FileSink:
+		entries, _ = os.ReadDir(dir)
+	case fs.ReadDirFS:
+		entries, _ = s.ReadDir(sinkName(dir))
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".go" || name == filepath.Base(filename) {
+			continue
+		}
+		src, err := sink.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name != pkg {
+			continue
+		}
+		for _, name := range topLevelNames(f) {
+			declared[name] = true
+		}
+	}
+	return declared
+}
+
+// topLevelNames returns the package level functions, types, variables and constants declared in f
+func topLevelNames(f *ast.File) []string {
+	var names []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						names = append(names, n.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// assumedPackageName returns the name of the package imported as importPath, as goimports assumes it: the last
+// element of the path, without a major version suffix, a "go-" prefix or anything after an invalid character
+func assumedPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil && path.Dir(importPath) != "." {
+			base = path.Base(path.Dir(importPath))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// isStdPath reports whether importPath is (syntactically) a standard library path: its first element has no dot
+func isStdPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+var goroot struct {
+	once     sync.Once
+	root     string              // GOROOT/src
+	packages map[string][]string // package name -> import paths, shortest first
+
+	lock    sync.Mutex
+	exports map[string]map[string]bool // import path -> exported names
+}
+
+// stdPackage returns the import path of the standard library package named name which exports all the symbols,
+// or "" if there is none. The shortest path wins when several packages match, as with goimports.
+func stdPackage(name string, symbols map[string]bool) string {
+	goroot.once.Do(scanStdlib)
+	for _, importPath := range goroot.packages[name] {
+		exports := stdExports(importPath)
+		found := true
+		for s := range symbols {
+			if !exports[s] {
+				found = false
+				break
+			}
+		}
+		if found {
+			return importPath
+		}
+	}
+	return ""
+}
+
+// scanStdlib lists the importable packages of GOROOT/src
+func scanStdlib() {
+	goroot.root = filepath.Join(build.Default.GOROOT, "src")
+	goroot.packages = map[string][]string{}
+	goroot.exports = map[string]map[string]bool{}
+	_ = filepath.WalkDir(goroot.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(goroot.root, p)
+		if err != nil {
+			return nil
+		}
+		switch d.Name() {
+		case "internal", "vendor", "testdata":
+			return filepath.SkipDir
+		}
+		if rel == "cmd" {
+			return filepath.SkipDir
+		}
+		if rel == "." || !hasGoFiles(p) {
+			return nil
+		}
+		importPath := filepath.ToSlash(rel)
+		name := assumedPackageName(importPath)
+		goroot.packages[name] = append(goroot.packages[name], importPath)
+		return nil
+	})
+	for _, paths := range goroot.packages {
+		sort.Slice(paths, func(i, j int) bool {
+			if len(paths[i]) != len(paths[j]) {
+				return len(paths[i]) < len(paths[j])
+			}
+			return paths[i] < paths[j]
+		})
+	}
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && !strings.HasSuffix(e.Name(), "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// stdExports returns the exported package level names of a standard library package
+func stdExports(importPath string) map[string]bool {
+	goroot.lock.Lock()
+	defer goroot.lock.Unlock()
+	if exports, ok := goroot.exports[importPath]; ok {
+		return exports
+	}
+	exports := map[string]bool{}
+	dir := filepath.Join(goroot.root, filepath.FromSlash(importPath))
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name == "main" || f.Name.Name == "documentation" {
+			continue
+		}
+		for _, n := range topLevelNames(f) {
+			if ast.IsExported(n) {
+				exports[n] = true
+			}
+		}
+	}
+	goroot.exports[importPath] = exports
+	return exports
+}
@@ -0,0 +1,117 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// hashData returns a hex encoded sha256 digest of data, walking it with reflection so that the result only
+// depends on the values reachable from data (not on pointer addresses or map iteration order).
+// Functions and channels are hashed by type only.
+func hashData(data interface{}) string {
+	h := sha256.New()
+	hashValue(h, reflect.ValueOf(data), make(map[uintptr]bool))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashValue(h hash.Hash, v reflect.Value, visiting map[uintptr]bool) {
+	if !v.IsValid() {
+		h.Write([]byte{0})
+		return
+	}
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		writeUint(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	writeString(v.Type().String())
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeString(v.String())
+	case reflect.Array, reflect.Slice:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			writeUint(math.MaxUint64)
+			return
+		}
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i), visiting)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			writeUint(math.MaxUint64)
+			return
+		}
+		// hash each key/value pair separately, and combine them in a deterministic order
+		pairs := make([][]byte, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			hp := sha256.New()
+			hashValue(hp, iter.Key(), visiting)
+			hashValue(hp, iter.Value(), visiting)
+			pairs = append(pairs, hp.Sum(nil))
+		}
+		sort.Slice(pairs, func(i, j int) bool { return bytes.Compare(pairs[i], pairs[j]) < 0 })
+		writeUint(uint64(len(pairs)))
+		for _, p := range pairs {
+			h.Write(p)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			writeString(t.Field(i).Name)
+			hashValue(h, v.Field(i), visiting)
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			writeUint(math.MaxUint64)
+			return
+		}
+		ptr := v.Pointer()
+		if visiting[ptr] {
+			writeString("cycle")
+			return
+		}
+		visiting[ptr] = true
+		hashValue(h, v.Elem(), visiting)
+		delete(visiting, ptr)
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint(math.MaxUint64)
+			return
+		}
+		hashValue(h, v.Elem(), visiting)
+	default:
+		// functions, channels, unsafe pointers
+		writeUint(uint64(v.Kind()))
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHashData(t *testing.T) {
+	type node struct {
+		Values map[string]int
+		Next   *node
+	}
+	build := func(v int) *node {
+		n := &node{Values: make(map[string]int)}
+		for i := 0; i < 100; i++ {
+			n.Values[fmt.Sprint(i)] = i * v
+		}
+		n.Next = &node{}
+		return n
+	}
+	if hashData(build(1)) != hashData(build(1)) {
+		t.Fatal("hash is not deterministic")
+	}
+	if hashData(build(1)) == hashData(build(2)) {
+		t.Fatal("different data have the same hash")
+	}
+}
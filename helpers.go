@@ -19,7 +19,7 @@ import (
 // Template helpers (txt/template)
 func helpers() template.FuncMap {
 	// functions used in template
-	return template.FuncMap{
+	funcs := template.FuncMap{
 		"add":        add,
 		"bits":       getBits,
 		"bytes":      intBytes, //TODO: Do this directly
@@ -53,6 +53,13 @@ func helpers() template.FuncMap {
 		"toUpper":    strings.ToUpper,
 		"words64":    bigIntToUint64SliceAsString,
 	}
+	for k, v := range bigHelpers() {
+		funcs[k] = v
+	}
+	for k, v := range fieldHelpers() {
+		funcs[k] = v
+	}
+	return funcs
 }
 
 func _select(condition bool, ifNot, ifSo interface{}) interface{} {
@@ -131,10 +138,12 @@ func toBigInt(a interface{}) (big.Int, error) {
 		return i, nil
 	case *big.Int:
 		return *i, nil
-	/*case string:
-	var res big.Int
-	res.SetString(i, 0)
-	return res, nil*/
+	case string:
+		var res big.Int
+		if _, ok := res.SetString(i, 0); !ok {
+			return res, fmt.Errorf("invalid integer %q", i)
+		}
+		return res, nil
 	default:
 		n, err := toInt64(i)
 		return *big.NewInt(n), err
@@ -290,7 +299,10 @@ func bigIntToUint64SliceAsString(in interface{}) (string, error) {
 	case *big.Int:
 		input = i
 	default:
-		return "", fmt.Errorf("unsupported type %T", in)
+		var err error
+		if input, err = toBig(in); err != nil {
+			return "", fmt.Errorf("unsupported type %T: %w", in, err)
+		}
 	}
 
 	builder := StringBuilderPool.Get().(*strings.Builder)
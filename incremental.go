@@ -0,0 +1,162 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// genCache skips the generation of the outputs whose inputs did not change since they were last generated.
+//
+// For each output, it stores in a local directory the key of its inputs (see genCache.key) and the hash of the
+// generated content. An output is skipped if its key is unchanged and the file on disk still has that content.
+// The directory can be deleted at any time; outputs are then regenerated.
+type genCache struct {
+	dir string
+}
+
+// cacheVersion is part of every cache key, and must be changed when the generation changes in a way that is
+// not captured by the key
+const cacheVersion = "bavard-cache-v1"
+
+// Cache returns a BatchGenerator option enabling incremental generation: entries whose templates, helper
+// functions, options and data (see Fingerprint) did not change since their output was generated are skipped.
+// The cache is stored in dir.
+func Cache(dir string) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.cache = &genCache{dir: dir}
+	}
+}
+
+// Fingerprint returns a bavard option setting the fingerprint of the data given to the templates, used as
+// cache key by incremental generation (see Cache). It must change whenever the data changes. By default, the
+// data is hashed with reflection, which can be slow on large data structures.
+func Fingerprint(fingerprint string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.fingerprint = fingerprint
+		return nil
+	}
+}
+
+func withGenCache(cache *genCache, dataHash func() string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.cache = cache
+		b.dataHash = dataHash
+		return nil
+	}
+}
+
+// lookup returns the cache key of output, and whether the file on disk is up to date with it.
+// key is empty if it can't be computed.
+func (c *genCache) lookup(b *Bavard, output string, templates []string, header []byte) (key string, upToDate bool) {
+	key, err := c.key(b, output, templates, header)
+	if err != nil {
+		return "", false
+	}
+	record, err := os.ReadFile(c.recordPath(output))
+	if err != nil {
+		return key, false
+	}
+	recordedKey, contentHash, ok := strings.Cut(strings.TrimSpace(string(record)), "\n")
+	if !ok || recordedKey != key {
+		return key, false
+	}
+	h, err := hashOutput(b.output(), output)
+	return key, err == nil && h == contentHash
+}
+
+// store records that output was generated with key
+func (c *genCache) store(sink Sink, output, key string) error {
+	h, err := hashOutput(sink, output)
+	if err != nil {
+		return err
+	}
+	_, err = writeFile(c.recordPath(output), []byte(key+"\n"+h+"\n"))
+	return err
+}
+
+// key hashes everything the content of output depends on
+func (c *genCache) key(b *Bavard, output string, templates []string, header []byte) (string, error) {
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", cacheVersion, bavardVersion(), stdlibHash(), abs)
+	fmt.Fprintf(h, "fmt=%t imports=%t\x00", b.fmt, b.imports)
+	h.Write(header)
+
+	names := make([]string, 0, len(b.funcs))
+	for name := range helpers() {
+		names = append(names, name)
+	}
+	for name := range b.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(h, "\x00%s\x00", strings.Join(names, ","))
+
+	for _, t := range templates {
+		content, err := readTemplate(b.templateFS, t)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", t, len(content))
+		h.Write(content)
+	}
+
+	if b.fingerprint != "" {
+		fmt.Fprintf(h, "fingerprint\x00%s", b.fingerprint)
+	} else {
+		fmt.Fprintf(h, "data\x00%s", b.dataHash())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *genCache) recordPath(output string) string {
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		abs = output
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16]))
+}
+
+func hashOutput(sink Sink, output string) (string, error) {
+	content, err := sink.ReadFile(output)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// bavardVersion returns the version of this module in the running binary, so that upgrading bavard
+// invalidates the cache
+func bavardVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	const path = "github.com/consensys/bavard"
+	if info.Main.Path == path {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			return dep.Version + " " + dep.Sum
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestIncrementalGeneration(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "x.tmpl", "var x = {{count .}}\n")
+	output := filepath.Join(dir, "x.go")
+	executions := 0
+	opts := []func(*Bavard) error{Verbose(false), Funcs(template.FuncMap{
+		"count": func(v interface{}) interface{} {
+			executions++
+			return v
+		},
+	})}
+
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", Cache(filepath.Join(dir, "cache")))
+	generate := func(data int, extraOpts ...func(*Bavard) error) {
+		t.Helper()
+		if err := bgen.GenerateWithOptions(data, "test", dir, append(opts, extraOpts...), Entry{File: output, Templates: []string{"x.tmpl"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generate(42)
+	generate(42)
+	if executions != 1 {
+		t.Fatalf("expected the second generation to be skipped, got %d executions", executions)
+	}
+	generate(43)
+	if executions != 2 {
+		t.Fatal("expected a data change to regenerate the file")
+	}
+
+	// a modified output is regenerated
+	if err := os.WriteFile(output, []byte("package test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	generate(43)
+	if got, _ := os.ReadFile(output); executions != 3 || !strings.Contains(string(got), "var x = 43") {
+		t.Fatalf("expected a modified output to be regenerated, got:\n%s", got)
+	}
+
+	// a caller supplied fingerprint replaces the data hash
+	generate(44, Fingerprint("v1"))
+	generate(45, Fingerprint("v1"))
+	if executions != 4 {
+		t.Fatalf("expected the fingerprint to be used as cache key, got %d executions", executions)
+	}
+
+	// deleting the cache regenerates everything
+	if err := os.RemoveAll(filepath.Join(dir, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	generate(45, Fingerprint("v1"))
+	if executions != 5 {
+		t.Fatal("expected generation after the cache was deleted")
+	}
+
+	// a check run doesn't update the cache
+	readCache := func() map[string]string {
+		t.Helper()
+		files := map[string]string{}
+		entries, err := os.ReadDir(filepath.Join(dir, "cache"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			content, _ := os.ReadFile(filepath.Join(dir, "cache", e.Name()))
+			files[e.Name()] = string(content)
+		}
+		return files
+	}
+	before := readCache()
+	generate(45, Fingerprint("v2"), CheckOnly(true))
+	if after := readCache(); fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Fatalf("check run modified the cache: %v -> %v", before, after)
+	}
+}
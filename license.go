@@ -0,0 +1,221 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// licenseHeader is the license written on top of generated files
+type licenseHeader struct {
+	text   string     // without comment markers; {{.Years}} and {{.Holder}} are substituted
+	holder string     // copyright holder
+	years  YearPolicy // used unless LicenseYears is given
+}
+
+// render returns the license as comment lines, the same for Go and assembly files
+func (l *licenseHeader) render(output string, years YearPolicy, now time.Time) (string, error) {
+	if years == nil {
+		years = l.years
+	}
+	y, err := years(output, now)
+	if err != nil {
+		return "", err
+	}
+	text := strings.NewReplacer("{{.Years}}", y, "{{.Holder}}", l.holder).Replace(l.text)
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case strings.HasPrefix(line, "//"):
+			// already a comment
+		case line == "":
+			line = "//"
+		default:
+			line = "// " + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// YearPolicy returns the years of the copyright notice of output, given the current time
+type YearPolicy func(output string, now time.Time) (string, error)
+
+// FixedYear is a YearPolicy always returning year
+func FixedYear(year int) YearPolicy {
+	return func(string, time.Time) (string, error) {
+		return strconv.Itoa(year), nil
+	}
+}
+
+// CurrentYear is a YearPolicy returning the current year
+func CurrentYear() YearPolicy {
+	return func(_ string, now time.Time) (string, error) {
+		return strconv.Itoa(now.Year()), nil
+	}
+}
+
+// YearRange is a YearPolicy returning "first-current year", or first if it is the current year
+func YearRange(first int) YearPolicy {
+	return func(_ string, now time.Time) (string, error) {
+		return yearRange(first, now.Year()), nil
+	}
+}
+
+// GitFirstYear is a YearPolicy returning the range from the year the output was first committed in git to the
+// current year. Outputs which are not committed yet (or not in a git repository) get the current year.
+func GitFirstYear() YearPolicy {
+	return func(output string, now time.Time) (string, error) {
+		first := now.Year()
+		cmd := exec.Command("git", "log", "--follow", "--format=%ad", "--date=format:%Y", "--", filepath.Base(output))
+		cmd.Dir = filepath.Dir(output)
+		if out, err := cmd.Output(); err == nil {
+			if fields := strings.Fields(string(out)); len(fields) != 0 {
+				// commits are listed from the most recent
+				if year, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+					first = year
+				}
+			}
+		}
+		return yearRange(first, now.Year()), nil
+	}
+}
+
+func yearRange(first, last int) string {
+	if last > first {
+		return fmt.Sprintf("%d-%d", first, last)
+	}
+	return strconv.Itoa(first)
+}
+
+// LicenseYears returns a bavard option setting how the years of the license header are computed.
+// By default, Apache2 uses YearRange and the other licenses use CurrentYear.
+func LicenseYears(policy YearPolicy) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.years = policy
+		return nil
+	}
+}
+
+const apache2Text = `Copyright {{.Years}} {{.Holder}}
+Licensed under the Apache License, Version 2.0. See the LICENSE file for details.`
+
+// Apache2Header returns a Apache2 header string. The current year is read as described in Clock.
+func Apache2Header(copyrightHolder string, year int) string {
+	now, err := defaultNow()
+	if err != nil {
+		now = time.Now()
+	}
+	l := licenseHeader{text: apache2Text, holder: copyrightHolder, years: YearRange(year)}
+	header, _ := l.render("", nil, now)
+	return header
+}
+
+// Apache2 returns a bavard option to be used in Generate writing an apache2 license header in the generated file
+func Apache2(copyrightHolder string, year int) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.license = &licenseHeader{text: apache2Text, holder: copyrightHolder, years: YearRange(year)}
+		return nil
+	}
+}
+
+// SPDX returns a bavard option writing a short license header with an SPDX license identifier
+// (for instance "Apache-2.0" or "MIT OR Apache-2.0") in the generated file
+func SPDX(identifier, copyrightHolder string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.license = &licenseHeader{
+			text:   "Copyright {{.Years}} {{.Holder}}\nSPDX-License-Identifier: " + identifier,
+			holder: copyrightHolder,
+			years:  CurrentYear(),
+		}
+		return nil
+	}
+}
+
+const mitText = `Copyright (c) {{.Years}} {{.Holder}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.`
+
+// MIT returns a bavard option writing the MIT license in the generated file
+func MIT(copyrightHolder string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.license = &licenseHeader{text: mitText, holder: copyrightHolder, years: CurrentYear()}
+		return nil
+	}
+}
+
+const bsd3Text = `Copyright (c) {{.Years}} {{.Holder}}
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`
+
+// BSD3 returns a bavard option writing the 3-clause BSD license in the generated file
+func BSD3(copyrightHolder string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.license = &licenseHeader{text: bsd3Text, holder: copyrightHolder, years: CurrentYear()}
+		return nil
+	}
+}
+
+// LicenseFile returns a bavard option writing the license read from path in the generated file.
+// Lines are turned into comments unless they already are, and {{.Years}} is replaced by the years of the
+// copyright notice (see LicenseYears).
+func LicenseFile(path string) func(*Bavard) error {
+	return func(b *Bavard) error {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b.license = &licenseHeader{text: string(bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))), years: CurrentYear()}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLicenses(t *testing.T) {
+	dir := t.TempDir()
+	generate := func(name, tmpl string, opts ...func(*Bavard) error) string {
+		t.Helper()
+		output := filepath.Join(dir, name)
+		opts = append([]func(*Bavard) error{Package("test"), Verbose(false), GeneratedBy("test")}, opts...)
+		if err := GenerateFromString(output, []string{tmpl}, nil, opts...); err != nil {
+			t.Fatal(err)
+		}
+		content, _ := os.ReadFile(output)
+		return string(content)
+	}
+
+	got := generate("x.go", "var x = 1\n", Apache2("Consensys Software Inc.", 2020), LicenseYears(FixedYear(2024)), BuildTag("amd64"))
+	want := "//go:build amd64\n\n// Copyright 2024 Consensys Software Inc.\n// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.\n\n// Code generated by test DO NOT EDIT\n\npackage test\n\nvar x = 1\n"
+	if got != want {
+		t.Fatalf("unexpected .go output:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = generate("x_amd64.s", "TEXT ·f(SB), $0\n", SPDX("Apache-2.0", "Consensys Software Inc."), LicenseYears(YearRange(2020)))
+	want = fmt.Sprintf("// Copyright 2020-%d Consensys Software Inc.\n// SPDX-License-Identifier: Apache-2.0\n\n// Code generated by test DO NOT EDIT\n\nTEXT ·f(SB), $0\n", time.Now().Year())
+	if got != want {
+		t.Fatalf("unexpected .s output:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = generate("doc.go", "// Package test is a test.\npackage test\n", BSD3("Consensys Software Inc."), LicenseYears(FixedYear(2024)))
+	if !strings.HasPrefix(got, "// Copyright (c) 2024 Consensys Software Inc.\n// All rights reserved.\n//\n// Redistribution") ||
+		!strings.HasSuffix(got, "DAMAGE.\n\n// Code generated by test DO NOT EDIT\n\n// Package test is a test.\npackage test\n") {
+		t.Fatalf("unexpected doc.go output:\n%s", got)
+	}
+
+	licenseFile := filepath.Join(dir, "LICENSE.header")
+	writeTemplate(t, dir, "LICENSE.header", "Copyright {{.Years}} Someone\n\nAll rights reserved.\n")
+	got = generate("y.go", "var y = 1\n", LicenseFile(licenseFile), LicenseYears(FixedYear(2021)))
+	if !strings.HasPrefix(got, "// Copyright 2021 Someone\n//\n// All rights reserved.\n\n// Code generated") {
+		t.Fatalf("unexpected output with license file:\n%s", got)
+	}
+	if strings.Contains(generate("z.go", "var z = 1\n", MIT("Someone")), "\t") {
+		t.Fatal("tabs in license header")
+	}
+}
+
+func TestGitFirstYear(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2019-06-01T00:00:00Z", "GIT_COMMITTER_DATE=2019-06-01T00:00:00Z",
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	output := filepath.Join(dir, "x.go")
+	if err := os.WriteFile(output, []byte("package test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "x.go")
+	git("commit", "-q", "-m", "x")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if years, err := GitFirstYear()(output, now); err != nil || years != "2019-2024" {
+		t.Fatalf("expected 2019-2024, got %q (%v)", years, err)
+	}
+	if years, _ := GitFirstYear()(filepath.Join(dir, "new.go"), now); years != "2024" {
+		t.Fatalf("expected 2024 for an uncommitted file, got %q", years)
+	}
+}
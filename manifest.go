@@ -0,0 +1,219 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ManifestEntry describes a file generated by a BatchGenerator, see Manifest.
+// Paths are relative to the directory of the manifest, with forward slashes.
+type ManifestEntry struct {
+	Path        string   `json:"path"`
+	Templates   []string `json:"templates"`
+	DataHash    string   `json:"dataHash"`    // sha256 of the data given to the templates, see hashData
+	ContentHash string   `json:"contentHash"` // sha256 of the generated file
+}
+
+type manifestFile struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// generatedHeader matches the standard header of generated Go files (https://go.dev/s/generatedcode)
+var generatedHeader = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.?$`)
+
+// manifest collects the files generated by a BatchGenerator
+type manifest struct {
+	path  string
+	dir   string // absolute directory of path; entries are relative to it
+	prune bool
+
+	lock  sync.Mutex
+	known map[string]bool          // outputs of all the entries given to the generator
+	files map[string]ManifestEntry // outputs generated
+}
+
+// Manifest returns a BatchGenerator option recording every file it generates (path, templates, data hash and
+// content hash) in a JSON manifest at path, written by WriteManifest.
+func Manifest(path string) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.initManifest().path = path
+	}
+}
+
+// Prune returns a BatchGenerator option. If set to true, WriteManifest removes the files listed in the previous
+// manifest which are not generated anymore. Files without a "Code generated ... DO NOT EDIT" header are never
+// removed. Has no effect without Manifest. Files are removed from the file system, whatever the Output.
+func Prune(v bool) func(*BatchGenerator) {
+	return func(b *BatchGenerator) {
+		b.initManifest().prune = v
+	}
+}
+
+func (b *BatchGenerator) initManifest() *manifest {
+	if b.manifest == nil {
+		b.manifest = &manifest{known: make(map[string]bool), files: make(map[string]ManifestEntry)}
+	}
+	return b.manifest
+}
+
+// ReadManifest reads a manifest written by BatchGenerator.WriteManifest. A missing file is an empty manifest.
+func ReadManifest(path string) ([]ManifestEntry, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m manifestFile
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m.Files, nil
+}
+
+// WriteManifest writes the manifest of the files generated so far (see Manifest). Entries which were not
+// generated by this run (filtered out with BAVARD_FILTER, failed or cancelled) keep their previous record.
+// With Prune, it removes the files of the previous manifest that no entry generates anymore, and returns them.
+func (b *BatchGenerator) WriteManifest() (removed []string, err error) {
+	m := b.manifest
+	if m == nil || m.path == "" {
+		return nil, errors.New("no manifest configured")
+	}
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	previous, err := ReadManifest(m.path)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	files := make([]ManifestEntry, 0, len(m.files))
+	for _, f := range m.files {
+		files = append(files, f)
+	}
+	var orphans []string
+	for _, f := range previous {
+		if _, ok := m.files[f.Path]; ok {
+			continue
+		}
+		if m.known[f.Path] {
+			files = append(files, f)
+		} else {
+			orphans = append(orphans, f.Path)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	content, err := json.MarshalIndent(manifestFile{Files: files}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writeFile(m.path, append(content, '\n')); err != nil {
+		return nil, err
+	}
+
+	if !m.prune {
+		return nil, nil
+	}
+	var errs []error
+	for _, orphan := range orphans {
+		path := filepath.Join(m.dir, filepath.FromSlash(orphan))
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !generatedHeader.Match(content) {
+			errs = append(errs, fmt.Errorf("%s: not removed, the file has no generated code header", path))
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, path)
+	}
+	return removed, errors.Join(errs...)
+}
+
+func (m *manifest) init() error {
+	if m.dir != "" {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(m.path))
+	if err != nil {
+		return err
+	}
+	m.dir = dir
+	return nil
+}
+
+// rel returns path relative to the manifest directory
+func (m *manifest) rel(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(m.dir, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// claim marks output as produced by an entry of the generator, generated or not
+func (m *manifest) claim(output string) error {
+	if err := m.init(); err != nil {
+		return err
+	}
+	rel, err := m.rel(output)
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	m.known[rel] = true
+	m.lock.Unlock()
+	return nil
+}
+
+// record adds the generated output to the manifest
+// Templates read from a fs.FS (fromFS) are recorded with their path in the fs.FS.
+func (m *manifest) record(sink Sink, output string, templates []string, fromFS bool, dataHash string) error {
+	contentHash, err := hashOutput(sink, output)
+	if err != nil {
+		return err
+	}
+	rel, err := m.rel(output)
+	if err != nil {
+		return err
+	}
+	entry := ManifestEntry{Path: rel, Templates: make([]string, len(templates)), DataHash: dataHash, ContentHash: contentHash}
+	for i, t := range templates {
+		if fromFS {
+			entry.Templates[i] = t
+		} else if entry.Templates[i], err = m.rel(t); err != nil {
+			return err
+		}
+	}
+	m.lock.Lock()
+	m.files[rel] = entry
+	m.lock.Unlock()
+	return nil
+}
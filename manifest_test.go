@@ -0,0 +1,58 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManifestPrune(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "x.tmpl", xTemplate)
+	manifestPath := filepath.Join(dir, "manifest.json")
+	entry := func(name string) Entry {
+		return Entry{File: filepath.Join(dir, name), Templates: []string{"x.tmpl"}}
+	}
+	opts := []func(*Bavard) error{Verbose(false)}
+
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", Manifest(manifestPath), Prune(true))
+	if err := bgen.GenerateWithOptions(42, "test", dir, opts, entry("a.go"), entry("b.go"), entry("c.go")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bgen.WriteManifest(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 || files[0].Path != "a.go" || files[0].Templates[0] != "x.tmpl" || files[0].DataHash != hashData(42) {
+		t.Fatalf("unexpected manifest %+v", files)
+	}
+
+	// next run: b.go and c.go are not generated anymore, and c.go was replaced by a hand-written file
+	if err := os.WriteFile(filepath.Join(dir, "c.go"), []byte("package test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bgen = NewBatchGenerator("Consensys Software Inc.", 2020, "bavard", Manifest(manifestPath), Prune(true))
+	if err := bgen.GenerateWithOptions(42, "test", dir, opts, entry("a.go")); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := bgen.WriteManifest()
+	if err == nil || !strings.Contains(err.Error(), "c.go") {
+		t.Fatalf("expected an error for the hand-written file, got %v", err)
+	}
+	if len(removed) != 1 || removed[0] != filepath.Join(dir, "b.go") {
+		t.Fatalf("unexpected removed files %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.go")); err != nil {
+		t.Fatal("hand-written file was removed")
+	}
+	if files, _ := ReadManifest(manifestPath); len(files) != 1 {
+		t.Fatalf("unexpected manifest %+v", files)
+	}
+}
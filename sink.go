@@ -0,0 +1,388 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink is where generated files are written, see Output. Implementations must be safe for concurrent use,
+// as batch generation writes files concurrently.
+type Sink interface {
+	// ReadFile returns the current content of the file name, or an error wrapping fs.ErrNotExist.
+	// It is used to leave identical files untouched, and in check mode.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile creates or replaces the file name
+	WriteFile(name string, content []byte) error
+}
+
+// Output returns a bavard option setting where the generated files are written. Defaults to FileSink.
+func Output(sink Sink) func(*Bavard) error {
+	return func(b *Bavard) error {
+		b.sink = sink
+		return nil
+	}
+}
+
+func (b *Bavard) output() Sink {
+	if b.sink == nil {
+		return FileSink{}
+	}
+	return b.sink
+}
+
+// FileSink writes to the file system. Files are replaced atomically: they are written to a temporary file,
+// synced, and renamed.
+type FileSink struct{}
+
+func (FileSink) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (FileSink) WriteFile(name string, content []byte) error {
+	return writeFileAtomic(name, content)
+}
+
+// MemorySink keeps the generated files in memory, typically to test generators without writing to the
+// working tree. Files are named by their output path, cleaned and with forward slashes.
+type MemorySink struct {
+	lock  sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemorySink returns an empty MemorySink
+func NewMemorySink() *MemorySink {
+	return &MemorySink{files: make(map[string][]byte)}
+}
+
+func (m *MemorySink) ReadFile(name string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	content, ok := m.files[sinkName(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+func (m *MemorySink) WriteFile(name string, content []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[sinkName(name)] = append([]byte(nil), content...)
+	return nil
+}
+
+// Files returns a copy of the files written so far
+func (m *MemorySink) Files() map[string][]byte {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	files := make(map[string][]byte, len(m.files))
+	for name, content := range m.files {
+		files[name] = append([]byte(nil), content...)
+	}
+	return files
+}
+
+// Open implements fs.FS. Only files written with a valid fs.FS name (relative, without "..") can be opened.
+func (m *MemorySink) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	// written contents are replaced, never modified: they can be read without holding the lock
+	if content, ok := m.files[name]; ok {
+		return &memFile{memInfo: memInfo{name: path.Base(name), size: int64(len(content))}, Reader: bytes.NewReader(content)}, nil
+	}
+	entries, ok := m.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memDir{memInfo: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (m *MemorySink) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	entries, ok := m.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// readDir returns the entries of the directory dir, sorted by name. The directories are those of the files
+// written; ok is false if dir has none (the root always exists).
+func (m *MemorySink) readDir(dir string) (entries []fs.DirEntry, ok bool) {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix, ok = "", true
+	}
+	seen := make(map[string]bool)
+	for name, content := range m.files {
+		rest, found := strings.CutPrefix(name, prefix)
+		if !found || !fs.ValidPath(name) {
+			continue
+		}
+		ok = true
+		elem, _, isDir := strings.Cut(rest, "/")
+		if seen[elem] {
+			continue
+		}
+		seen[elem] = true
+		if isDir {
+			entries = append(entries, memInfo{name: elem, dir: true})
+		} else {
+			entries = append(entries, memInfo{name: elem, size: int64(len(content))})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, ok
+}
+
+// memInfo describes a file or directory of a MemorySink, as a fs.FileInfo and a fs.DirEntry
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string               { return i.name }
+func (i memInfo) Size() int64                { return i.size }
+func (i memInfo) ModTime() time.Time         { return time.Time{} }
+func (i memInfo) IsDir() bool                { return i.dir }
+func (i memInfo) Sys() interface{}           { return nil }
+func (i memInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i memInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// memFile is an open file of a MemorySink
+type memFile struct {
+	memInfo
+	*bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.memInfo, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open directory of a MemorySink
+type memDir struct {
+	memInfo
+	entries []fs.DirEntry
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.memInfo, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// sinkName normalizes an output path into a file name for in-memory sinks
+func sinkName(name string) string {
+	return filepath.ToSlash(filepath.Clean(name))
+}
+
+// OverlaySink keeps the generated files in memory, on top of a base fs.FS: files which were not generated are
+// read from the base. Used with os.DirFS, it generates (or checks) a tree without modifying it, outputs being
+// given relative to the root of the base.
+type OverlaySink struct {
+	*MemorySink
+	base fs.FS
+}
+
+// NewOverlaySink returns an OverlaySink over base
+func NewOverlaySink(base fs.FS) *OverlaySink {
+	return &OverlaySink{MemorySink: NewMemorySink(), base: base}
+}
+
+func (o *OverlaySink) ReadFile(name string) ([]byte, error) {
+	content, err := o.MemorySink.ReadFile(name)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return content, err
+	}
+	return fs.ReadFile(o.base, sinkName(name))
+}
+
+func (o *OverlaySink) WriteFile(name string, content []byte) error {
+	if !fs.ValidPath(sinkName(name)) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return o.MemorySink.WriteFile(name, content)
+}
+
+// Open implements fs.FS: generated files take precedence over the files of the base
+func (o *OverlaySink) Open(name string) (fs.File, error) {
+	f, err := o.MemorySink.Open(name)
+	if err == nil {
+		if info, err := f.Stat(); err == nil && !info.IsDir() {
+			return f, nil
+		}
+		f.Close()
+	}
+	return o.base.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS, merging the entries of the generated files and of the base
+func (o *OverlaySink) ReadDir(name string) ([]fs.DirEntry, error) {
+	generated, errGenerated := o.MemorySink.ReadDir(name)
+	base, errBase := fs.ReadDir(o.base, name)
+	if errGenerated != nil && errBase != nil {
+		return nil, errBase
+	}
+	entries := make(map[string]fs.DirEntry, len(generated)+len(base))
+	for _, e := range base {
+		entries[e.Name()] = e
+	}
+	for _, e := range generated {
+		entries[e.Name()] = e
+	}
+	merged := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		merged = append(merged, e)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
+
+// ArchiveSink writes the generated files to a tar or zip archive. Files are kept in memory and the archive is
+// written, with files sorted by name, by Close; the archive is identical for identical outputs.
+type ArchiveSink struct {
+	*MemorySink
+
+	// ModTime is the modification time of the archived files. Defaults to SOURCE_DATE_EPOCH if set, and to
+	// the Unix epoch otherwise.
+	ModTime time.Time
+
+	w      io.Writer
+	format string
+}
+
+// NewTarSink returns an ArchiveSink writing a tar archive to w
+func NewTarSink(w io.Writer) *ArchiveSink {
+	return &ArchiveSink{MemorySink: NewMemorySink(), w: w, format: "tar"}
+}
+
+// NewZipSink returns an ArchiveSink writing a zip archive to w
+func NewZipSink(w io.Writer) *ArchiveSink {
+	return &ArchiveSink{MemorySink: NewMemorySink(), w: w, format: "zip"}
+}
+
+// WriteFile adds a file to the archive. name must be relative and not contain "..".
+func (a *ArchiveSink) WriteFile(name string, content []byte) error {
+	if !fs.ValidPath(sinkName(name)) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return a.MemorySink.WriteFile(name, content)
+}
+
+// Close writes the archive. It doesn't close the underlying writer.
+func (a *ArchiveSink) Close() error {
+	files := a.Files()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	modTime := a.ModTime
+	if modTime.IsZero() {
+		epoch, ok, err := sourceDateEpoch()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			epoch = time.Unix(0, 0)
+		}
+		modTime = epoch
+	}
+	modTime = modTime.UTC()
+
+	switch a.format {
+	case "tar":
+		tw := tar.NewWriter(a.w)
+		for _, name := range names {
+			hdr := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     name,
+				Mode:     0o644,
+				Size:     int64(len(files[name])),
+				ModTime:  modTime,
+				Format:   tar.FormatPAX,
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(files[name]); err != nil {
+				return err
+			}
+		}
+		return tw.Close()
+	case "zip":
+		zw := zip.NewWriter(a.w)
+		for _, name := range names {
+			hdr := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+			hdr.SetMode(0o644)
+			w, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(files[name]); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	default:
+		return fmt.Errorf("unsupported archive format %q", a.format)
+	}
+}
+
+var (
+	_ Sink = FileSink{}
+	_ Sink = (*MemorySink)(nil)
+	_ Sink = (*OverlaySink)(nil)
+	_ Sink = (*ArchiveSink)(nil)
+
+	_ fs.ReadDirFS = (*MemorySink)(nil)
+	_ fs.ReadDirFS = (*OverlaySink)(nil)
+)
@@ -0,0 +1,65 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSinks(t *testing.T) {
+	// memory: nothing is written to disk
+	mem := NewMemorySink()
+	var results []Result
+	onResult := OnResult(func(r Result) { results = append(results, r) })
+	for i := 0; i < 2; i++ {
+		generateX(t, "gen/x.go", 42, Output(mem), onResult)
+	}
+	if _, err := os.Stat("gen"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("memory sink wrote to the file system")
+	}
+	if got := string(mem.Files()["gen/x.go"]); !strings.HasSuffix(got, "var x = 42\n") {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+	if len(results) != 2 || results[0].Outcome != Created || results[1].Outcome != Unchanged {
+		t.Fatalf("unexpected results %v", results)
+	}
+	generateX(t, "gen/sub/y.go", 43, Output(mem), onResult)
+	// ReadFile follows the Sink contract, which accepts any output path: only the fs.FS view is tested
+	if err := fstest.TestFS(struct{ fs.ReadDirFS }{mem}, "gen/x.go", "gen/sub/y.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	// overlay: check generated code against a tree without modifying it
+	base := fstest.MapFS{"gen/x.go": &fstest.MapFile{Data: mem.Files()["gen/x.go"]}}
+	overlay := NewOverlaySink(base)
+	generateX(t, "gen/x.go", 42, Output(overlay), CheckOnly(true))
+	generateX(t, "gen/y.go", 43, Output(overlay))
+	if entries, err := fs.ReadDir(overlay, "gen"); err != nil || len(entries) != 2 {
+		t.Fatalf("expected generated and base files in the overlay, got %v (%v)", entries, err)
+	}
+
+	// zip archive
+	var buf bytes.Buffer
+	archive := NewZipSink(&buf)
+	for _, name := range []string{"b/y.go", "a/x.go"} {
+		generateX(t, name, 42, Output(archive))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "a/x.go" || zr.File[1].Name != "b/y.go" {
+		t.Fatalf("unexpected archive content %v", zr.File)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// markFunc is the template function inserted in front of each template node. When executed, it records
+// the current output offset, so that a position in the generated code can be traced back to the template
+// line that produced it.
+const markFunc = "_bavardMark"
+
+// markPrototype is copied for each instrumented node; its string argument is then replaced by the node location
+var markPrototype = func() *parse.ActionNode {
+	tree := parse.New("mark")
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(`{{`+markFunc+` ""}}`, "", "", make(map[string]*parse.Tree)); err != nil {
+		panic(err)
+	}
+	return tree.Root.Nodes[0].(*parse.ActionNode)
+}()
+
+// location kinds, stored as first character of a mark argument
+const (
+	locText   = 'T' // text node, copied verbatim in the output
+	locAction = 'A' // action node
+)
+
+// instrument inserts marks in front of all the nodes of the templates defined in tmpl
+func instrument(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil || isInstrumented(t.Tree.Root) {
+			continue
+		}
+		instrumentList(t.Tree, t.Tree.Root)
+	}
+}
+
+func isInstrumented(list *parse.ListNode) bool {
+	if len(list.Nodes) == 0 {
+		return false
+	}
+	action, ok := list.Nodes[0].(*parse.ActionNode)
+	return ok && isMark(action)
+}
+
+// isMark reports whether action was inserted by instrument
+func isMark(action *parse.ActionNode) bool {
+	if action.Pipe == nil || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) == 0 {
+		return false
+	}
+	ident, ok := action.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == markFunc
+}
+
+func instrumentList(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, 2*len(list.Nodes))
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.TextNode:
+			nodes = append(nodes, newMark(tree, n, locText))
+		case *parse.ActionNode, *parse.TemplateNode:
+			nodes = append(nodes, newMark(tree, n, locAction))
+		case *parse.IfNode:
+			nodes = append(nodes, newMark(tree, n, locAction))
+			instrumentList(tree, n.List)
+			instrumentList(tree, n.ElseList)
+		case *parse.RangeNode:
+			nodes = append(nodes, newMark(tree, n, locAction))
+			instrumentList(tree, n.List)
+			instrumentList(tree, n.ElseList)
+		case *parse.WithNode:
+			nodes = append(nodes, newMark(tree, n, locAction))
+			instrumentList(tree, n.List)
+			instrumentList(tree, n.ElseList)
+		}
+		nodes = append(nodes, n)
+	}
+	list.Nodes = nodes
+}
+
+func newMark(tree *parse.Tree, n parse.Node, kind byte) parse.Node {
+	location, _ := tree.ErrorContext(n)
+	mark := markPrototype.Copy().(*parse.ActionNode)
+	arg := mark.Pipe.Cmds[0].Args[1].(*parse.StringNode)
+	arg.Text = string(kind) + location
+	arg.Quoted = strconv.Quote(arg.Text)
+	return mark
+}
+
+// sourceMap records, while a template executes, which template location produced which part of the output
+type sourceMap struct {
+	out     *bytes.Buffer
+	sources *templateSources
+	marks   []mark
+}
+
+type mark struct {
+	offset   int
+	location string
+}
+
+func newSourceMap(out *bytes.Buffer, sources *templateSources) *sourceMap {
+	return &sourceMap{out: out, sources: sources}
+}
+
+// funcs returns the FuncMap binding the mark function to this source map
+func (sm *sourceMap) funcs() template.FuncMap {
+	return template.FuncMap{
+		markFunc: func(location string) string {
+			sm.marks = append(sm.marks, mark{offset: sm.out.Len(), location: location})
+			return ""
+		},
+	}
+}
+
+// lookup returns the template file and line which produced the byte at offset in src (the generated code).
+// It returns an empty file name if the offset is not covered by a template (license header, package clause...)
+func (sm *sourceMap) lookup(src []byte, offset int) (file string, line int) {
+	if sm == nil {
+		return "", 0
+	}
+	i := sort.Search(len(sm.marks), func(i int) bool { return sm.marks[i].offset > offset })
+	if i == 0 {
+		return "", 0
+	}
+	m := sm.marks[i-1]
+	name, line, ok := splitLocation(m.location[1:])
+	if !ok {
+		return "", 0
+	}
+	if m.location[0] == locText && offset <= len(src) {
+		line += bytes.Count(src[m.offset:offset], []byte{'\n'})
+	}
+	return sm.sources.resolve(name, line)
+}
+
+// splitLocation parses a text/template location "name:line:col"
+func splitLocation(location string) (name string, line int, ok bool) {
+	i := strings.LastIndexByte(location, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	j := strings.LastIndexByte(location[:i], ':')
+	if j < 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(location[j+1 : i])
+	if err != nil {
+		return "", 0, false
+	}
+	return location[:j], line, true
+}
+
+// templateSources maps the names and lines of parsed templates back to the sources they were read from
+type templateSources struct {
+	fsys  fs.FS             // nil for the OS file system
+	files map[string]string // template name -> file path
+
+	// aggregated is the name of the template built by concatenating parts (see GenerateFromString)
+	aggregated string
+	parts      []string
+	firstLines []int // first line of each part in the aggregated template
+}
+
+// sourcesFromFiles maps template names to the files they were read from, in fsys or in the OS file system
+// if fsys is nil
+func sourcesFromFiles(fsys fs.FS, paths []string) *templateSources {
+	s := &templateSources{fsys: fsys, files: make(map[string]string, len(paths))}
+	for _, p := range paths {
+		if fsys != nil {
+			s.files[path.Base(p)] = p
+		} else {
+			s.files[filepath.Base(p)] = p
+		}
+	}
+	return s
+}
+
+func sourcesFromStrings(name string, parts []string) *templateSources {
+	s := &templateSources{aggregated: name, parts: parts, firstLines: make([]int, len(parts))}
+	line := 1
+	for i, p := range parts {
+		s.firstLines[i] = line
+		line += strings.Count(p, "\n")
+	}
+	return s
+}
+
+// resolve returns the origin of line in the template name
+func (s *templateSources) resolve(name string, line int) (string, int) {
+	if s == nil {
+		return name, line
+	}
+	if s.firstLines != nil && name == s.aggregated {
+		i := sort.SearchInts(s.firstLines, line+1) - 1
+		// a part not ending with a newline shares its last line with the next one; attribute it to the first
+		for i > 0 && line == s.firstLines[i] && s.firstLines[i-1] == s.firstLines[i] {
+			i--
+		}
+		return fmt.Sprintf("templates[%d]", i), line - s.firstLines[i] + 1
+	}
+	if file, ok := s.files[name]; ok {
+		return file, line
+	}
+	return name, line
+}
+
+// text returns the source of a template, given either the name it was parsed under or a file (or part) as
+// returned by resolve
+func (s *templateSources) text(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	if strings.HasPrefix(name, stdlibDir+"/") {
+		b, err := stdlib.ReadFile(name)
+		return string(b), err == nil
+	}
+	if s.parts != nil {
+		if name == s.aggregated {
+			return aggregate(s.parts), true
+		}
+		var i int
+		if _, err := fmt.Sscanf(name, "templates[%d]", &i); err == nil && i >= 0 && i < len(s.parts) {
+			return s.parts[i], true
+		}
+		return "", false
+	}
+	file, ok := s.files[name]
+	if !ok {
+		for _, p := range s.files {
+			if p == name {
+				file, ok = p, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	b, err := readTemplate(s.fsys, file)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// readTemplate reads a template file in fsys, or in the OS file system if fsys is nil
+func readTemplate(fsys fs.FS, file string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(file)
+	}
+	return fs.ReadFile(fsys, file)
+}
@@ -0,0 +1,91 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"rsc.io/tmplfunc"
+)
+
+// stdlib holds templates available to all generators. They are defined with tmplfunc and are called as
+// functions:
+//
+//	{{ limbArray "q" .Q }}                                 var q = [...]uint64{...}, limbs of a big.Int
+//	{{ unrolledLoop 4 "z[%[1]d] = x[%[1]d] + y[%[1]d]" }}  one line per index, with printf
+//	{{ goTestTable "TestAdd" "a, b, want int" (list "1, 2, 3" "2, 2, 4") "..." }}
+//	{{ goBenchmark "BenchmarkAdd" "add(x, y)" "x, y := 1, 2" }}
+//
+// Templates of the generators may redefine them, but functions installed with Funcs may not: the stdlib
+// functions would silently replace them.
+//
+//go:embed stdlib/*.tmpl
+var stdlib embed.FS
+
+const stdlibDir = "stdlib"
+
+// StdlibTemplates returns the names of the templates of the standard library, with their arguments
+func StdlibTemplates() []string {
+	var names []string
+	t := template.New("").Funcs(helpers())
+	if err := parseStdlib(t); err != nil {
+		panic(err)
+	}
+	for _, tmpl := range t.Templates() {
+		if tmpl.Name() != "" && !strings.HasPrefix(tmpl.Name(), stdlibDir+"/") {
+			names = append(names, tmpl.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stdlibFuncs is the set of function names defined by the standard library templates
+var stdlibFuncs = sync.OnceValue(func() map[string]bool {
+	names := make(map[string]bool)
+	for _, t := range StdlibTemplates() {
+		names[strings.Fields(t)[0]] = true
+	}
+	return names
+})
+
+// parseStdlib adds the standard library templates (and functions calling them) to the set of tmpl.
+// It must be called before parsing the templates using them, and after installing the helpers (see helpers).
+func parseStdlib(tmpl *template.Template) error {
+	files, err := fs.Glob(stdlib, stdlibDir+"/*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := stdlib.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		// the file name is in the location of the parse nodes, and is resolved by templateSources.text
+		if err := tmplfunc.Parse(tmpl.New(file), string(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stdlibHash returns a digest of the standard library templates, part of the cache keys (see Cache)
+var stdlibHash = sync.OnceValue(func() string {
+	h := sha256.New()
+	files, _ := fs.Glob(stdlib, stdlibDir+"/*.tmpl")
+	for _, file := range files {
+		content, _ := stdlib.ReadFile(file)
+		h.Write([]byte(path.Base(file)))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+})
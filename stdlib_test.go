@@ -0,0 +1,52 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestStdlib(t *testing.T) {
+	q, _ := new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+	output := filepath.Join(t.TempDir(), "out.go")
+	const tmpl = `{{ limbArray "q" .Q }}
+
+func f(x, y, z []uint64) {
+	{{ unrolledLoop 2 "z[%[1]d] = x[%[1]d] + y[%[1]d]" }}
+}
+`
+	if err := GenerateFromString(output, []string{tmpl}, map[string]interface{}{"Q": q}, Package("test"), Verbose(false), Format(true)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(output)
+	for _, want := range []string{
+		"// q = 21888242871839275222246405745257275088696311157297823662689037894645226208583\n",
+		"var q = [...]uint64{4332616871279656263, 10917124144477883021, 13281191951274694749, 3486998266802970665}\n",
+		"\tz[0] = x[0] + y[0]\n\tz[1] = x[1] + y[1]\n}\n",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+
+	// generators may redefine the standard templates
+	redefined := `{{define "limbArray name value"}}// {{.name}}{{end}}{{ limbArray "q" 3 }}`
+	if err := GenerateFromString(output, []string{redefined}, nil, Package("test"), Verbose(false)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(output); !strings.HasSuffix(string(got), "// q") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// but functions installed with Funcs may not
+	funcs := template.FuncMap{"limbArray": func(string, int) string { return "" }}
+	if err := GenerateFromString(output, []string{`{{ limbArray "q" 3 }}`}, nil, Package("test"), Verbose(false), Funcs(funcs)); err == nil || !strings.Contains(err.Error(), `"limbArray"`) {
+		t.Fatalf("expected an error for a function named after a standard template, got %v", err)
+	}
+}
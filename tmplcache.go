@@ -0,0 +1,179 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"rsc.io/tmplfunc"
+)
+
+// templateCache holds parsed template sets, so that the entries of a batch generation sharing the same
+// templates parse them only once. Each use gets a clone of the parsed set.
+type templateCache struct {
+	lock    sync.Mutex
+	entries map[string]*cachedTemplate
+}
+
+type cachedTemplate struct {
+	once sync.Once
+	tmpl *template.Template
+	err  error
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{entries: make(map[string]*cachedTemplate)}
+}
+
+// get returns a clone of the template set parsed from paths (in fsys, or in the OS file system if fsys is
+// nil), with funcs installed.
+// The cache key includes the names of the functions (which are resolved at parse time) but not their
+// implementation, and the size and modification time (or, in fsys, the content) of the files.
+func (c *templateCache) get(fsys fs.FS, paths []string, funcs template.FuncMap) (*template.Template, error) {
+	key, err := templateCacheKey(fsys, paths, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = new(cachedTemplate)
+		c.entries[key] = e
+	}
+	c.lock.Unlock()
+
+	e.once.Do(func() {
+		e.tmpl, e.err = parseFiles(fsys, paths, funcs)
+	})
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	clone, err := e.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	clone.Funcs(funcs)
+	// functions defined by templates must execute the cloned templates, not the cached ones
+	if err := tmplfunc.Funcs(clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+func templateCacheKey(fsys fs.FS, paths []string, funcs template.FuncMap) (string, error) {
+	var sb strings.Builder
+	if fsys != nil {
+		// file systems such as embed.FS have no modification times, and different file systems may
+		// have the same paths
+		fmt.Fprintf(&sb, "fs:%T\x00", fsys)
+		for _, p := range paths {
+			content, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256(content)
+			fmt.Fprintf(&sb, "%s\x00%x\x00", p, sum)
+		}
+	} else {
+		for _, p := range paths {
+			info, err := os.Stat(p)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&sb, "%s\x00%d\x00%d\x00", p, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sb.WriteString(strings.Join(names, "\x00"))
+	return sb.String(), nil
+}
+
+// parseFiles parses the template files (in fsys, or in the OS file system if fsys is nil) into a new,
+// instrumented (see instrument), template set
+func parseFiles(fsys fs.FS, paths []string, funcs template.FuncMap) (*template.Template, error) {
+	name := filepath.Base(paths[0])
+	if fsys != nil {
+		name = path.Base(paths[0])
+	}
+	tmpl := template.New(name).Funcs(funcs)
+	if err := parseStdlib(tmpl); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if fsys == nil {
+		err = tmplfunc.ParseFiles(tmpl, paths...)
+	} else {
+		err = parseFS(tmpl, fsys, paths)
+	}
+	if err != nil {
+		return nil, err
+	}
+	instrument(tmpl)
+	return tmpl, nil
+}
+
+// parseFS is tmplfunc.ParseFiles for files in fsys: templates are named after the base name of their file,
+// and the templates defined by any of the files can be called as functions from all of them.
+func parseFS(tmpl *template.Template, fsys fs.FS, paths []string) error {
+	texts := make([]string, len(paths))
+	for i, p := range paths {
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		texts[i] = string(content)
+	}
+
+	// template functions must be defined before parsing; install placeholders, replaced by tmplfunc.Funcs
+	trees := make(map[string]*parse.Tree)
+	for i, text := range texts {
+		tree := parse.New(path.Base(paths[i]))
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(text, "", "", trees); err != nil {
+			return err
+		}
+	}
+	placeholders := make(template.FuncMap)
+	for name := range trees {
+		if f := strings.Fields(name); len(f) != 0 && templateFuncName.MatchString(f[0]) {
+			placeholders[f[0]] = func(...interface{}) (string, error) {
+				return "", errors.New("template function called before tmplfunc.Funcs")
+			}
+		}
+	}
+	tmpl.Funcs(placeholders)
+
+	for i, text := range texts {
+		t := tmpl
+		if name := path.Base(paths[i]); name != tmpl.Name() {
+			t = tmpl.New(name)
+		}
+		if _, err := t.Parse(text); err != nil {
+			return err
+		}
+	}
+	return tmplfunc.Funcs(tmpl)
+}
+
+// templateFuncName matches the names of templates tmplfunc makes callable as functions (first word only)
+var templateFuncName = regexp.MustCompile(`\A[_\pL][_\pL\p{Nd}]*\z`)
@@ -0,0 +1,44 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "x.tmpl", `{{define "double x"}}{{mul .x 2}}{{end}}var x = {{double .}}
+`)
+	entries := []Entry{
+		{File: filepath.Join(dir, "a.go"), Templates: []string{"x.tmpl"}},
+		{File: filepath.Join(dir, "b.go"), Templates: []string{"x.tmpl"}},
+	}
+	bgen := NewBatchGenerator("Consensys Software Inc.", 2020, "bavard")
+	opts := []func(*Bavard) error{Verbose(false)}
+	for _, data := range []int{21, 2} {
+		if err := bgen.GenerateWithOptions(data, "test", dir, opts, entries...); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if e.Templates[0] != "x.tmpl" {
+				t.Fatalf("entry templates modified: %v", e.Templates)
+			}
+			got, err := os.ReadFile(e.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("var x = %d\n", 2*data); !strings.HasSuffix(string(got), want) {
+				t.Fatalf("%s: expected %q, got:\n%s", e.File, want, got)
+			}
+		}
+	}
+	if n := len(bgen.templates.entries); n != 1 {
+		t.Fatalf("expected templates to be parsed once, got %d template sets", n)
+	}
+}
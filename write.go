@@ -0,0 +1,109 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Outcome describes what happened to an output file
+type Outcome int
+
+const (
+	Created   Outcome = iota // the file did not exist
+	Updated                  // the file existed with a different content and was replaced
+	Unchanged                // the file already had the generated content and was left untouched
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	case Unchanged:
+		return "unchanged"
+	default:
+		return "unknown"
+	}
+}
+
+// Result reports the outcome of the generation of one file, see OnResult
+type Result struct {
+	Output  string
+	Outcome Outcome
+}
+
+// writeFile writes content to the file output (see writeOutput)
+func writeFile(output string, content []byte) (Outcome, error) {
+	return writeOutput(FileSink{}, output, content)
+}
+
+// writeOutput writes content to output in sink, unless output already holds content
+func writeOutput(sink Sink, output string, content []byte) (Outcome, error) {
+	existing, err := sink.ReadFile(output)
+	outcome := Updated
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		outcome = Created
+	case err != nil:
+		return 0, err
+	case bytes.Equal(existing, content):
+		return Unchanged, nil
+	}
+	if err := sink.WriteFile(output, content); err != nil {
+		return 0, err
+	}
+	return outcome, nil
+}
+
+// writeFileAtomic writes content to output atomically: the content is written to a temporary file in the same
+// directory, synced, and renamed over output. The permissions of an existing output are preserved.
+func writeFileAtomic(output string, content []byte) error {
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(output); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(output)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(output)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// on success, the temporary file has been renamed and this is a no-op
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return err
+	}
+
+	// persist the rename; not supported on all platforms, hence best effort
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
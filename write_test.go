@@ -0,0 +1,49 @@
+// Copyright 2020-2024 Consensys Software Inc.
+// Licensed under the Apache License, Version 2.0. See the LICENSE file for details.
+
+package bavard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteOutcomes(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "sub", "out.go")
+	var results []Result
+	onResult := OnResult(func(r Result) { results = append(results, r) })
+
+	generateX(t, output, 42, onResult)
+	// identical content: the file must not be rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(output, past, past); err != nil {
+		t.Fatal(err)
+	}
+	generateX(t, output, 42, onResult)
+	if info, err := os.Stat(output); err != nil || !info.ModTime().Equal(past) {
+		t.Fatalf("unchanged file was rewritten (%v)", err)
+	}
+	generateX(t, output, 43, onResult)
+
+	want := []Outcome{Created, Unchanged, Updated}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %v", len(want), results)
+	}
+	for i := range want {
+		if results[i].Output != output || results[i].Outcome != want[i] {
+			t.Fatalf("result %d: expected %s, got %s", i, want[i], results[i].Outcome)
+		}
+	}
+
+	// no temporary file left behind
+	files, err := os.ReadDir(filepath.Dir(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected only the output file, got %d files", len(files))
+	}
+}